@@ -0,0 +1,401 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/token"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+)
+
+// ApiGen is the payload of an `// apigen:api` comment.
+type ApiGen struct {
+	Url         string   `json:"url"`
+	Auth        bool     `json:"auth"`
+	Method      Methods  `json:"method"`
+	Roles       []string `json:"roles"`
+	AuthSchemes []string `json:"auth_schemes"`
+	Timeout     string   `json:"timeout"`
+	RateLimit   string   `json:"rate_limit"`
+	MaxInFlight int      `json:"max_in_flight"`
+	Status      int      `json:"status"`
+	Body        string   `json:"body"`
+	Stream      string   `json:"stream"`
+}
+
+// Methods is the "method" of an annotation: either one HTTP method or a list
+// of them. No method at all means any method.
+type Methods []string
+
+func (m *Methods) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*m = nil
+		if one != "" {
+			*m = Methods{one}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("method must be a string or a list of strings")
+	}
+	*m = list
+	return nil
+}
+
+// httpMethods are the methods an annotation may list.
+var httpMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// parseMethods upper-cases and dedups the methods of an annotation, unknown
+// ones are reported at pos.
+func parseMethods(rep *reporter, pos token.Pos, methods Methods) []string {
+	var parsed []string
+	seen := make(map[string]bool)
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if !httpMethods[method] {
+			rep.errorf(pos, "unknown HTTP method %q", method)
+			continue
+		}
+		if !seen[method] {
+			seen[method] = true
+			parsed = append(parsed, method)
+		}
+	}
+	return parsed
+}
+
+// parseRoles dedups the roles of an annotation, empty ones are reported at
+// pos.
+func parseRoles(rep *reporter, pos token.Pos, roles []string) []string {
+	var parsed []string
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		if role == "" {
+			rep.errorf(pos, "empty role in roles")
+			continue
+		}
+		if !seen[role] {
+			seen[role] = true
+			parsed = append(parsed, role)
+		}
+	}
+	return parsed
+}
+
+// parseSchemes lower-cases and dedups the auth schemes of an annotation,
+// the ones serve does not know are reported at pos.
+func parseSchemes(rep *reporter, pos token.Pos, schemes []string) []string {
+	var parsed []string
+	seen := make(map[string]bool)
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(scheme)
+		if _, ok := serve.DefaultSchemes[scheme]; !ok {
+			rep.errorf(pos, "unknown auth scheme %q", scheme)
+			continue
+		}
+		if !seen[scheme] {
+			seen[scheme] = true
+			parsed = append(parsed, scheme)
+		}
+	}
+	return parsed
+}
+
+// parseTimeout normalizes the timeout of an annotation, like "1500ms" to
+// "1.5s". A malformed or non-positive one is reported at pos.
+func parseTimeout(rep *reporter, pos token.Pos, timeout string) string {
+	if timeout == "" {
+		return ""
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		rep.errorf(pos, "timeout %q is not a positive duration like \"2s\"", timeout)
+		return ""
+	}
+	return d.String()
+}
+
+// parseRateLimit reads the rate_limit of an annotation: a number of requests
+// per a unit ("10/s", "100/m", "1/h") or per a duration ("5/10s"). A
+// malformed one is reported at pos.
+func parseRateLimit(rep *reporter, pos token.Pos, rateLimit string) *RateLimit {
+	if rateLimit == "" {
+		return nil
+	}
+	requests, per, _ := strings.Cut(rateLimit, "/")
+	n, err := strconv.Atoi(requests)
+	if per != "" && per[0] >= 'a' && per[0] <= 'z' {
+		per = "1" + per
+	}
+	d, perErr := time.ParseDuration(per)
+	if err != nil || n <= 0 || perErr != nil || d <= 0 {
+		rep.errorf(pos, "rate_limit %q is not a number of requests per a duration like \"10/s\"", rateLimit)
+		return nil
+	}
+	return &RateLimit{Requests: n, Per: d.String()}
+}
+
+// ApiService is the payload of an `// apigen:service` comment on a type:
+// the prefix of the urls of its endpoints and the defaults of their
+// annotations, which an apigen:api key overrides.
+type ApiService struct {
+	Prefix string `json:"prefix"`
+	ApiGen
+}
+
+// parseBody checks the body of an annotation: "form" (the default, which is
+// returned as ""), "json" or "auto". Anything else is reported at pos.
+func parseBody(rep *reporter, pos token.Pos, body string) string {
+	switch body {
+	case "", "form":
+		return ""
+	case "json", "auto":
+		return body
+	}
+	rep.errorf(pos, "body must be \"form\", \"json\" or \"auto\", not %q", body)
+	return ""
+}
+
+const (
+	apiGenPrefix     = "// apigen:api "
+	apiServicePrefix = "// apigen:service "
+)
+
+var (
+	// apiGenKeys are the keys an apigen:api payload may have.
+	apiGenKeys = jsonKeys(reflect.TypeOf(ApiGen{}))
+	// apiServiceKeys are the keys an apigen:service payload may have: a
+	// prefix and the defaults of every apigen:api key but url.
+	apiServiceKeys = func() map[string]bool {
+		keys := jsonKeys(reflect.TypeOf(ApiGen{}))
+		delete(keys, "url")
+		keys["prefix"] = true
+		return keys
+	}()
+)
+
+// parseApiGen decodes the payload of an apigen:api comment on top of the
+// defaults of the service of the method. ok is false when nothing could be
+// decoded.
+func parseApiGen(rep *reporter, comment *ast.Comment, defaults ApiGen) (ApiGen, bool) {
+	apiGen := defaults
+	// decoding reuses the arrays of slices, the defaults are shared
+	apiGen.Method = append(Methods(nil), defaults.Method...)
+	apiGen.Roles = append([]string(nil), defaults.Roles...)
+	apiGen.AuthSchemes = append([]string(nil), defaults.AuthSchemes...)
+	ok := parseAnnotation(rep, comment, apiGenPrefix, apiGenKeys, &apiGen)
+	return apiGen, ok
+}
+
+// parseApiService decodes the payload of an apigen:service comment, a
+// malformed prefix is reported. ok is false when nothing could be decoded.
+func parseApiService(rep *reporter, comment *ast.Comment) (ApiService, bool) {
+	var service ApiService
+	if !parseAnnotation(rep, comment, apiServicePrefix, apiServiceKeys, &service) {
+		return service, false
+	}
+	if service.Prefix != "" && (!strings.HasPrefix(service.Prefix, "/") || strings.HasSuffix(service.Prefix, "/")) {
+		rep.errorf(comment.Slash, "prefix %q must start with / and not end with one", service.Prefix)
+	}
+	return service, true
+}
+
+// parseAnnotation decodes the JSON payload of comment, which starts with
+// prefix, into v. Malformed JSON, keys missing from keys and values of the
+// wrong type are reported at the offending position of the comment.
+func parseAnnotation(rep *reporter, comment *ast.Comment, prefix string, keys map[string]bool, v interface{}) bool {
+	name := strings.TrimSpace(strings.TrimPrefix(prefix, "//"))
+	payload := []byte(strings.TrimPrefix(comment.Text, prefix))
+	base := comment.Slash + token.Pos(len(prefix))
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		rep.errorf(base+jsonErrorOffset(err), "malformed %s annotation: %v", name, err)
+		return false
+	}
+	for key := range raw {
+		if !keys[key] {
+			offset := bytes.Index(payload, []byte(strconv.Quote(key)))
+			rep.errorf(base+token.Pos(offset), "unknown %s key %q", name, key)
+		}
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		rep.errorf(base+jsonErrorOffset(err), "bad %s annotation: %v", name, err)
+		return false
+	}
+	return true
+}
+
+// jsonErrorOffset is the offset into the payload encoding/json blames.
+func jsonErrorOffset(err error) token.Pos {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+		return token.Pos(syntaxErr.Offset - 1)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Offset > 0 {
+		return token.Pos(typeErr.Offset - 1)
+	}
+	return 0
+}
+
+// jsonKeys lists the json names of the fields of the struct type t.
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// parseValidator reads the apivalidator tag of a params field:
+// required, paramname=, enum=a|b, default=, min= and max=, for uploads
+// maxsize= (in bytes) and mimetype=a/b|c/*, and for times layout=.
+func parseValidator(rep *reporter, paramField structField) *Field {
+	field := &Field{
+		Name:      paramField.Name,
+		Kind:      paramField.Kind,
+		ParamName: strings.ToLower(paramField.Name),
+	}
+	for _, option := range strings.Split(paramField.Tag, ",") {
+		if option == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "required":
+			field.Required = true
+		case "paramname":
+			field.ParamName = value
+		case "enum":
+			field.Enum = strings.Split(value, "|")
+		case "default":
+			field.Default = value
+		case "min":
+			field.Min = value
+		case "max":
+			field.Max = value
+		case "maxsize":
+			field.MaxSize = value
+		case "mimetype":
+			field.MimeTypes = strings.Split(value, "|")
+		case "layout":
+			field.Layout = value
+		default:
+			rep.errorf(paramField.Pos, "field %s: unknown apivalidator option %q", paramField.Name, key)
+			continue
+		}
+		if key != "required" && value == "" {
+			rep.errorf(paramField.Pos, "field %s: apivalidator option %s needs a value", paramField.Name, key)
+		}
+	}
+
+	upload := field.Kind == "file" || field.Kind == "bytes"
+	for _, option := range [][2]string{{"min", field.Min}, {"max", field.Max}, {"default", field.Default}, {"enum", strings.Join(field.Enum, "|")}} {
+		if upload && option[1] != "" {
+			rep.errorf(paramField.Pos, "field %s: apivalidator %s does not apply to uploads", paramField.Name, option[0])
+		}
+	}
+	for _, option := range [][2]string{{"maxsize", field.MaxSize}, {"mimetype", strings.Join(field.MimeTypes, "|")}} {
+		if !upload && option[1] != "" {
+			rep.errorf(paramField.Pos, "field %s: apivalidator %s only applies to uploads", paramField.Name, option[0])
+		}
+	}
+
+	// bools and times have no order, and times no literals
+	var unordered [][2]string
+	switch field.Kind {
+	case "time":
+		unordered = append(unordered, [2]string{"default", field.Default})
+		fallthrough
+	case "bool":
+		unordered = append(unordered, [2]string{"min", field.Min}, [2]string{"max", field.Max}, [2]string{"enum", strings.Join(field.Enum, "|")})
+	}
+	for _, option := range unordered {
+		if option[1] != "" {
+			rep.errorf(paramField.Pos, "field %s: apivalidator %s does not apply to %s fields", paramField.Name, option[0], field.Kind)
+		}
+	}
+	if field.Kind != "time" && field.Layout != "" {
+		rep.errorf(paramField.Pos, "field %s: apivalidator layout only applies to time fields", paramField.Name)
+	}
+
+	// lengths are numbers whatever the kind, values are of the kind
+	lengths := [][2]string{{"maxsize", field.MaxSize}}
+	var values [][2]string
+	switch field.Kind {
+	case "string":
+		lengths = append(lengths, [2]string{"min", field.Min}, [2]string{"max", field.Max})
+	case "file", "bytes", "time":
+	default:
+		values = append(values, [2]string{"min", field.Min}, [2]string{"max", field.Max}, [2]string{"default", field.Default})
+		for _, value := range field.Enum {
+			values = append(values, [2]string{"enum", value})
+		}
+	}
+	for _, length := range lengths {
+		if option, value := length[0], length[1]; value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				rep.errorf(paramField.Pos, "field %s: apivalidator %s=%s is not a number", paramField.Name, option, value)
+			}
+		}
+	}
+	for _, kindValue := range values {
+		if option, value := kindValue[0], kindValue[1]; value != "" {
+			if problem := checkValue(field.Kind, value); problem != "" {
+				rep.errorf(paramField.Pos, "field %s: apivalidator %s=%s %s", paramField.Name, option, value, problem)
+			}
+		}
+	}
+	return field
+}
+
+// checkValue tells what is wrong with value as a value of a field of kind,
+// or "" when nothing is.
+func checkValue(kind, value string) string {
+	var err error
+	switch {
+	case kind == "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "is not a bool"
+		}
+		return ""
+	case kind == "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return `is not a duration like "2s"`
+		}
+		return ""
+	case strings.HasPrefix(kind, "uint"):
+		_, err = strconv.ParseUint(value, 10, kindBits(kind))
+	case strings.HasPrefix(kind, "float"):
+		_, err = strconv.ParseFloat(value, kindBits(kind))
+	default:
+		_, err = strconv.ParseInt(value, 10, kindBits(kind))
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return "is out of the range of " + kind
+	} else if err != nil {
+		return "is not a number"
+	}
+	return ""
+}
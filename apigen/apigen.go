@@ -0,0 +1,62 @@
+// Package apigen generates HTTP handlers for the methods and functions of a
+// package that are marked with an `// apigen:api {...}` comment.
+//
+// Generation runs in three steps that can also be called one by one:
+// LoadPackage parses and type-checks the package, Collect builds the model of
+// its APIs (a File) and Render executes the templates on it. Generate chains
+// them for the common case, BuildModel stops at the model.
+package apigen
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// Config describes one run of the generator.
+type Config struct {
+	// Input is the package to generate for: its directory or any of its files.
+	Input string
+	// Output is the file the generated code is meant for. It is left out of
+	// the parsed package so that a stale version of it does not get in the way.
+	Output string
+	// TemplatesDir optionally holds *.tmpl files overriding built-in templates.
+	TemplatesDir string
+}
+
+// Generate returns the formatted generated code for cfg.Input. When the
+// package has problems the error is Diagnostics listing all of them.
+func Generate(cfg Config) ([]byte, error) {
+	tmpl, err := LoadTemplates(cfg.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	model, err := BuildModel(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, model)
+}
+
+// BuildModel loads cfg.Input and returns the model of its APIs, without
+// generating anything.
+func BuildModel(cfg Config) (*File, error) {
+	var skip []string
+	if cfg.Output != "" {
+		skip = append(skip, cfg.Output)
+	}
+	pkg, err := LoadPackage(cfg.Input, skip...)
+	if err != nil {
+		return nil, err
+	}
+	return Collect(pkg)
+}
+
+// Render executes the "file" template of tmpl on file and formats the result.
+// If the result is not valid Go, it is returned unformatted with the error.
+func Render(tmpl *template.Template, file *File) ([]byte, error) {
+	var raw bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&raw, "file", file); err != nil {
+		return nil, err
+	}
+	return Format(raw.Bytes())
+}
@@ -0,0 +1,203 @@
+package apigen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate internal/testapi/api_handlers.go")
+
+// testAPI is a package the generated code of which is committed, built and
+// tested like any other code.
+var testAPI = filepath.Join("internal", "testapi")
+
+func TestGenerate(t *testing.T) {
+	golden := filepath.Join(testAPI, "api_handlers.go")
+	src, err := Generate(Config{Input: testAPI, Output: golden})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if *update {
+		if err := os.WriteFile(golden, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := UnifiedDiff(golden, "generated", want, src); diff != "" {
+		t.Errorf("generated code does not match, rerun with -update if this is expected:\n%s", diff)
+	}
+}
+
+func TestTemplatesDir(t *testing.T) {
+	src, err := Generate(Config{
+		Input:        testAPI,
+		Output:       filepath.Join(testAPI, "api_handlers.go"),
+		TemplatesDir: filepath.Join("testdata", "templates"),
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	// errors is overridden, every other template is the built-in one
+	for _, want := range []string{
+		"http.Error(w, message, status)",
+		"func (srv *UserApi) ServeHTTP(",
+		"func (srv *UserApi) handlerProfile(",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %s in the generated code", want)
+		}
+	}
+	if strings.Contains(string(src), `"error": message`) {
+		t.Error("the built-in errors template is still used")
+	}
+}
+
+func TestBuildModel(t *testing.T) {
+	model, err := BuildModel(Config{Input: testAPI, Output: filepath.Join(testAPI, "api_handlers.go")})
+	if err != nil {
+		t.Fatalf("BuildModel: %v", err)
+	}
+	if len(model.APIs) == 0 || model.APIs[0].Name != "UserApi" || len(model.APIs[0].Endpoints) != 5 {
+		t.Fatalf("expected UserApi with five endpoints first, got %+v", model.APIs)
+	}
+	profile := model.APIs[0].Endpoints[1]
+	if profile.Name != "Profile" || profile.URL != "/user/profile" || profile.ResultType != "*User" {
+		t.Errorf("unexpected endpoint %+v", profile)
+	}
+	// Limit is promoted from the embedded Paging
+	if len(profile.Fields) != 2 {
+		t.Fatalf("expected two params, got %d", len(profile.Fields))
+	}
+	limit := profile.Fields[0]
+	if limit.Name != "Limit" || limit.Kind != "int" || limit.Min != "1" || limit.Max != "100" || limit.Default != "10" {
+		t.Errorf("unexpected param %+v", limit)
+	}
+	if login := profile.Fields[1]; login.ParamName != "user" || !login.Required {
+		t.Errorf("unexpected param %+v", login)
+	}
+}
+
+func TestBuildModelSkipsGenerated(t *testing.T) {
+	// without an output file, the generated one is still left out: its
+	// handlers and router would clash with the ones of the model
+	model, err := BuildModel(Config{Input: testAPI})
+	if err != nil {
+		t.Fatalf("BuildModel: %v", err)
+	}
+	if len(model.APIs) == 0 || model.APIs[0].Name != "UserApi" {
+		t.Fatalf("expected UserApi first, got %+v", model.APIs)
+	}
+}
+
+func TestGenerateDiagnostics(t *testing.T) {
+	_, err := Generate(Config{Input: filepath.Join("testdata", "bad")})
+	diags, ok := err.(Diagnostics)
+	if !ok {
+		t.Fatalf("expected Diagnostics, got %v", err)
+	}
+	want := []string{
+		`bad.go:11:2: field Name: unknown apivalidator option "mni"`,
+		`bad.go:12:2: field Age: apivalidator min=x is not a number`,
+		`bad.go:13:2: field Score has unsupported type complex128`,
+		`bad.go:16:29: unknown apigen:api key "methd"`,
+		`bad.go:21:28: malformed apigen:api annotation: invalid character '}' looking for beginning of object key string`,
+		`bad.go:26:1: bad url segment "x{y}", path params take a whole segment: {name}`,
+		`bad.go:26:1: path param {id} is not the paramname of a field of Params`,
+		`bad.go:31:1: unknown HTTP method "FETCH"`,
+		`bad.go:36:1: Api.X and Api.V both serve any method of /x`,
+		`bad.go:41:1: empty role in roles`,
+		`bad.go:46:1: unknown auth scheme "digest"`,
+		`bad.go:51:1: timeout "2" is not a positive duration like "2s"`,
+		`bad.go:56:1: rate_limit "10 per second" is not a number of requests per a duration like "10/s"`,
+		`bad.go:61:1: max_in_flight must be positive, not -1`,
+		`bad.go:66:1: prefix "svc/" must start with / and not end with one`,
+		`bad.go:66:38: unknown apigen:service key "url"`,
+		`bad.go:69:1: status 302 is not a success status`,
+		`bad.go:74:1: body must be "form", "json" or "auto", not "xml"`,
+		`bad.go:80:2: field File: apivalidator min does not apply to uploads`,
+		`bad.go:80:2: field File: apivalidator maxsize=1k is not a number`,
+		`bad.go:81:2: field Name: apivalidator mimetype only applies to uploads`,
+		`bad.go:89:1: stream only applies to methods returning a channel, not M`,
+		`bad.go:94:1: timeout does not apply to the stream of L`,
+		`bad.go:99:1: K returns a send-only channel, it can not be streamed`,
+		`bad.go:104:1: stream must be "ndjson", "sse" or "auto", not "csv"`,
+		`bad.go:110:30: context.Context must be the first param of I`,
+		`bad.go:115:51: H has more than one param struct, it takes at most (ctx context.Context, in ParamStruct)`,
+		`bad.go:120:40: param id of G is int, not a struct`,
+		`bad.go:125:17: F must return error or (T, error), not (*Params)`,
+		`bad.go:129:1: path param {id} needs a param struct, E has none`,
+		`bad.go:137:6: receiver of D must be T or *T of a non-generic type T`,
+		`bad.go:146:1: C and B both serve any method of /c`,
+		`bad.go:151:6: CHandler is declared already, the generated handler of C needs the name`,
+		`bad.go:154:2: field On: apivalidator min does not apply to bool fields`,
+		`bad.go:155:2: field Small: apivalidator max=300 is out of the range of uint8`,
+		`bad.go:156:2: field Ratio: apivalidator min=x is not a number`,
+		`bad.go:157:2: field Wait: apivalidator max=2 is not a duration like "2s"`,
+		`bad.go:158:2: field At: apivalidator default does not apply to time fields`,
+		`bad.go:159:2: field Name: apivalidator layout only applies to time fields`,
+		`bad.go:177:1: Api.GetItem and Api.DropItem serve the same path, /item/{slug} must name its path params like /item/{id}`,
+		`bad.go:183:2: field Avatar is an upload, a json body can not carry it`,
+		`bad.go:192:1: Twice has more than one apigen:api annotation`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %d:\n%v", len(want), len(diags), diags)
+	}
+	for i, diag := range diags {
+		if got := diag.String(); !strings.HasSuffix(got, want[i]) {
+			t.Errorf("diagnostic %d: expected %q, got %q", i, want[i], got)
+		}
+	}
+}
+
+func TestGenerateImportClash(t *testing.T) {
+	// the params are of a package named json, like encoding/json
+	src, err := Generate(Config{Input: filepath.Join("testdata", "imports")})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for _, want := range []string{
+		`"encoding/json"`,
+		`json2 "github.com/TerionGVS5/hw5_codegen/apigen/testdata/imports/json"`,
+		"var params json2.In",
+		"json.Marshal(",
+		// Wait is declared as a time.Duration in the imported package
+		"value, err := time.ParseDuration(raw)",
+		"params.Wait = json2.Wait(value)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %s in the generated code:\n%s", want, src)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	b := []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n")
+	want := `--- a
++++ b
+@@ -2,9 +2,10 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+ 9
+ 10
++11
+`
+	if got := UnifiedDiff("a", "b", a, b); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if got := UnifiedDiff("a", "b", a, a); got != "" {
+		t.Errorf("expected no diff for equal input, got\n%s", got)
+	}
+}
@@ -0,0 +1,69 @@
+package apigen
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem found in the parsed package.
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// Diagnostics is every problem found in a run, in source order. It is
+// returned as an error so that all of them are reported at once.
+type Diagnostics []Diagnostic
+
+func (ds Diagnostics) Error() string {
+	lines := make([]string, len(ds))
+	for i, d := range ds {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reporter collects diagnostics while the package is walked.
+type reporter struct {
+	fset  *token.FileSet
+	diags Diagnostics
+}
+
+// errorf reports a problem at pos. A param struct shared by several methods
+// is checked once per method, the same diagnostic is only kept once.
+func (rep *reporter) errorf(pos token.Pos, format string, args ...interface{}) {
+	diag := Diagnostic{
+		Pos:     rep.fset.Position(pos),
+		Message: fmt.Sprintf(format, args...),
+	}
+	for _, seen := range rep.diags {
+		if seen == diag {
+			return
+		}
+	}
+	rep.diags = append(rep.diags, diag)
+}
+
+// err returns the collected diagnostics sorted by position, or nil.
+func (rep *reporter) err() error {
+	if len(rep.diags) == 0 {
+		return nil
+	}
+	sort.SliceStable(rep.diags, func(i, j int) bool {
+		a, b := rep.diags[i].Pos, rep.diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return rep.diags
+}
@@ -0,0 +1,124 @@
+package apigen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// UnifiedDiff describes how to turn a into b in the unified format of
+// `diff -u`. It returns "" when they are equal.
+func UnifiedDiff(nameA, nameB string, a, b []byte) string {
+	linesA, linesB := splitLines(string(a)), splitLines(string(b))
+	ops := diffLines(linesA, linesB)
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// find the next change and the end of its hunk
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		from := max(start-diffContext, 0)
+		end := start
+		for unchanged := 0; end < len(ops) && unchanged <= 2*diffContext; end++ {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		// trim trailing context down to diffContext lines
+		for end > start && ops[end-1].kind == ' ' {
+			end--
+		}
+		end = min(end+diffContext, len(ops))
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		hunk := ops[from:end]
+		lineA, lineB := hunk[0].lineA, hunk[0].lineB
+		countA, countB := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(lineA, countA), hunkRange(lineB, countB))
+		for _, op := range hunk {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.text)
+		}
+		start = end
+	}
+	return out.String()
+}
+
+// diffOp is a line of the edit script: ' ' kept, '-' removed, '+' added.
+// lineA and lineB are the 0-based positions of the line in a and b.
+type diffOp struct {
+	kind         byte
+	text         string
+	lineA, lineB int
+}
+
+// diffLines computes the edit script through the longest common subsequence
+// of the lines; generated files are small enough for the quadratic table.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunkRange formats the "start,count" of a hunk header, 1-based.
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line+1)
+	}
+	return fmt.Sprintf("%d,%d", line+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
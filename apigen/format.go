@@ -0,0 +1,85 @@
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+)
+
+// generatedHeader marks the output as generated, see
+// https://golang.org/s/generatedcode.
+const generatedHeader = "// Code generated by handlers_gen. DO NOT EDIT.\n\n"
+
+// Format turns the raw template output into the file that is written:
+// the generated header is prepended, imports nothing refers to are dropped
+// and the result is gofmt-ed.
+func Format(raw []byte) ([]byte, error) {
+	src := append([]byte(generatedHeader), raw...)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		// hand back the unformatted code, it is easier to debug a template with
+		return src, fmt.Errorf("generated code does not parse: %v", err)
+	}
+	pruneImports(file)
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, file); err != nil {
+		return src, err
+	}
+	return out.Bytes(), nil
+}
+
+// pruneImports removes the imports that file does not use.
+func pruneImports(file *ast.File) {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		var specs []ast.Spec
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+			name := path.Base(importPath)
+			if importSpec.Name != nil {
+				name = importSpec.Name.Name
+			}
+			if used[name] || name == "_" || name == "." {
+				specs = append(specs, spec)
+			}
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		genDecl.Specs = specs
+		decls = append(decls, genDecl)
+	}
+	file.Decls = decls
+
+	file.Imports = file.Imports[:0]
+	for _, decl := range decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			for _, spec := range genDecl.Specs {
+				file.Imports = append(file.Imports, spec.(*ast.ImportSpec))
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package apigen
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+)
+
+// servePath is the import path of the package generated code runs with.
+var servePath = reflect.TypeOf(serve.Principal{}).PkgPath()
+
+// Collect walks the annotated methods of pkg and builds the model of its APIs,
+// the data the templates are executed with.
+// Every problem found on the way is reported, the error is then Diagnostics.
+func Collect(pkg *Package) (*File, error) {
+	rep := &reporter{fset: pkg.Fset}
+	model := &File{Package: pkg.Name, UploadedFile: pkg.uploadedFile}
+	// every package the built-in templates may refer to, the ones the
+	// generated code ends up not using are pruned after formatting
+	imports := importSet{
+		"context":       "context",
+		"encoding/json": "json",
+		"net/http":      "http",
+		"strconv":       "strconv",
+		"strings":       "strings",
+		"time":          "time",
+		servePath:       "serve",
+	}
+	apis := make(map[string]*API)
+	services := collectServices(rep, pkg)
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Doc == nil {
+				continue
+			}
+			annotated := false
+			for _, comment := range funcDecl.Doc.List {
+				if !strings.HasPrefix(comment.Text, apiGenPrefix) {
+					continue
+				}
+				if annotated {
+					rep.errorf(comment.Slash, "%s has more than one apigen:api annotation", funcDecl.Name.Name)
+					continue
+				}
+				annotated = true
+				recv, ok := receiver(rep, funcDecl)
+				if !ok {
+					continue
+				}
+				service := services[recv]
+				apiGen, ok := parseApiGen(rep, comment, service.ApiGen)
+				if !ok {
+					continue
+				}
+
+				// fields of a struct with problems are still checked, so
+				// that all of them are reported in one run
+				sig, ok := pkg.signature(rep, funcDecl)
+				if !ok {
+					continue
+				}
+				endpoint := &Endpoint{
+					Recv:        recv,
+					Name:        funcDecl.Name.Name,
+					URL:         service.Prefix + apiGen.Url,
+					Auth:        apiGen.Auth || len(apiGen.Roles) > 0 || len(apiGen.AuthSchemes) > 0,
+					Roles:       parseRoles(rep, comment.Slash, apiGen.Roles),
+					AuthSchemes: parseSchemes(rep, comment.Slash, apiGen.AuthSchemes),
+					Timeout:     parseTimeout(rep, comment.Slash, apiGen.Timeout),
+					RateLimit:   parseRateLimit(rep, comment.Slash, apiGen.RateLimit),
+					MaxInFlight: apiGen.MaxInFlight,
+					Status:      http.StatusOK,
+					Body:        parseBody(rep, comment.Slash, apiGen.Body),
+					HTTPMethods: parseMethods(rep, comment.Slash, apiGen.Method),
+					pos:         comment.Slash,
+					Context:     sig.Context,
+				}
+				if sig.Param != nil {
+					endpoint.ParamType = pkg.typeName(sig.Param, imports)
+				}
+				if sig.Result != nil {
+					// only described, the generated code never spells it out
+					endpoint.ResultType = pkg.typeName(sig.Result, importSet{})
+					endpoint.StatusFromResult = isStatusCoder(sig.Result)
+				}
+				endpoint.Stream = streamFormat(rep, comment.Slash, funcDecl.Name.Name, apiGen.Stream, sig.Result)
+				if endpoint.Stream != "" && endpoint.Timeout != "" {
+					rep.errorf(comment.Slash, "timeout does not apply to the stream of %s", funcDecl.Name.Name)
+					endpoint.Timeout = ""
+				}
+				if apiGen.Status != 0 {
+					if apiGen.Status < 200 || apiGen.Status > 299 {
+						rep.errorf(comment.Slash, "status %d is not a success status", apiGen.Status)
+					}
+					endpoint.Status = apiGen.Status
+				}
+				if apiGen.MaxInFlight < 0 {
+					rep.errorf(comment.Slash, "max_in_flight must be positive, not %d", apiGen.MaxInFlight)
+				}
+				endpoint.PathParams = pathParams(rep, comment.Slash, endpoint.URL)
+				bound := make(map[string]bool)
+				for _, paramField := range sig.Fields {
+					field := parseValidator(rep, paramField)
+					if paramField.Named != nil {
+						field.Type = pkg.typeName(paramField.Named, imports)
+					}
+					field.Source = "form"
+					if upload := field.Kind == "file" || field.Kind == "bytes"; upload && endpoint.Body == "json" {
+						rep.errorf(paramField.Pos, "field %s is an upload, a json body can not carry it", field.Name)
+					} else if !upload && endpoint.Body != "" {
+						field.Source = "body"
+					}
+					for _, name := range endpoint.PathParams {
+						if name == field.ParamName {
+							field.Source = "path"
+							bound[name] = true
+						}
+					}
+					endpoint.Fields = append(endpoint.Fields, field)
+				}
+				for _, name := range endpoint.PathParams {
+					if !bound[name] && sig.Param == nil {
+						rep.errorf(comment.Slash, "path param {%s} needs a param struct, %s has none", name, endpoint.Name)
+					} else if !bound[name] {
+						rep.errorf(comment.Slash, "path param {%s} is not the paramname of a field of %s", name, endpoint.ParamType)
+					}
+				}
+
+				api, ok := apis[recv]
+				if !ok {
+					api = &API{Name: recv, Prefix: service.Prefix}
+					apis[recv] = api
+					model.APIs = append(model.APIs, api)
+				}
+				api.Endpoints = append(api.Endpoints, endpoint)
+			}
+		}
+	}
+
+	for _, api := range model.APIs {
+		checkRoutes(rep, api)
+		if api.Name == "" {
+			checkFuncNames(rep, pkg, api)
+		}
+	}
+
+	for _, importPath := range imports.sorted() {
+		spec := Import{Path: importPath}
+		if imports[importPath] != path.Base(importPath) {
+			spec.Name = imports[importPath]
+		}
+		model.Imports = append(model.Imports, spec)
+	}
+	if err := rep.err(); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// receiver returns the name of the type funcDecl is a method of, T or *T, or
+// "" for a package-level function. Other receivers are reported.
+func receiver(rep *reporter, funcDecl *ast.FuncDecl) (string, bool) {
+	if funcDecl.Recv == nil {
+		return "", true
+	}
+	recvType := funcDecl.Recv.List[0].Type
+	if star, ok := recvType.(*ast.StarExpr); ok {
+		recvType = star.X
+	}
+	ident, ok := recvType.(*ast.Ident)
+	if !ok {
+		rep.errorf(funcDecl.Recv.Pos(), "receiver of %s must be T or *T of a non-generic type T", funcDecl.Name.Name)
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// checkFuncNames reports the declarations of pkg that clash with the names
+// generated for api, the annotated package-level functions: the handler of
+// each of them and the router.
+func checkFuncNames(rep *reporter, pkg *Package, api *API) {
+	scope := pkg.Types.Scope()
+	if obj := scope.Lookup(routerName); obj != nil {
+		rep.errorf(obj.Pos(), "%s is declared already, the generated router of the package functions needs the name", routerName)
+	}
+	for _, endpoint := range api.Endpoints {
+		if obj := scope.Lookup(endpoint.FuncHandler()); obj != nil {
+			rep.errorf(obj.Pos(), "%s is declared already, the generated handler of %s needs the name", obj.Name(), endpoint.Name)
+		}
+	}
+}
+
+// collectServices finds the apigen:service comments of the types of pkg, by
+// type name.
+func collectServices(rep *reporter, pkg *Package) map[string]ApiService {
+	services := make(map[string]ApiService)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				if doc == nil {
+					continue
+				}
+				for _, comment := range doc.List {
+					if !strings.HasPrefix(comment.Text, apiServicePrefix) {
+						continue
+					}
+					if _, ok := services[typeSpec.Name.Name]; ok {
+						rep.errorf(comment.Slash, "%s has more than one apigen:service annotation", typeSpec.Name.Name)
+						continue
+					}
+					if service, ok := parseApiService(rep, comment); ok {
+						services[typeSpec.Name.Name] = service
+					}
+				}
+			}
+		}
+	}
+	return services
+}
@@ -0,0 +1,55 @@
+// Package testapi is generated for by the apigen tests: api_handlers.go must
+// stay what the generator makes of it, api_test.go checks how it behaves.
+package testapi
+
+//go:generate go run ../../../handlers_gen . api_handlers.go
+
+import (
+	"context"
+	"fmt"
+)
+
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
+
+type UserApi struct{}
+
+type User struct {
+	ID    int    `json:"id,omitempty"`
+	Login string `json:"login"`
+	Level int    `json:"level"`
+}
+
+// apigen:api {"url": "/user/create", "auth": true, "method": "POST"}
+func (srv *UserApi) Create(ctx context.Context, in CreateParams) (*User, error) {
+	return &User{Login: in.Login, Level: in.Level}, nil
+}
+
+// apigen:api {"url": "/user/profile"}
+func (srv *UserApi) Profile(ctx context.Context, in ProfileParams) (*User, error) {
+	if in.Login == "nobody" {
+		return nil, ApiError{404, fmt.Errorf("user not exist")}
+	}
+	return &User{Login: in.Login}, nil
+}
+
+// apigen:api {"url": "/user/{id}/level/{level}"}
+func (srv *UserApi) Level(ctx context.Context, in LevelParams) (*User, error) {
+	return &User{ID: in.ID, Level: in.Level}, nil
+}
+
+// apigen:api {"url": "/user/{id}", "method": "GET"}
+func (srv *UserApi) Get(ctx context.Context, in GetParams) (*User, error) {
+	return &User{ID: in.ID, Login: "getter"}, nil
+}
+
+// apigen:api {"url": "/user/{id}", "method": ["put", "PATCH"]}
+func (srv *UserApi) Update(ctx context.Context, in UpdateParams) (*User, error) {
+	return &User{ID: in.ID, Login: in.Login}, nil
+}
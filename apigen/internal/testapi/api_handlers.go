@@ -0,0 +1,1594 @@
+// Code generated by handlers_gen. DO NOT EDIT.
+
+package testapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func writeErrorResponse(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"error": message,
+	})
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeResponse(w http.ResponseWriter, status int, response interface{}) {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"error":    "",
+		"response": response,
+	})
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// UploadedFile is a file of a multipart/form-data request, see
+// serve.UploadedFile.
+type UploadedFile = serve.UploadedFile
+
+// matchPath reports whether the path of r matches pattern, where a {name}
+// segment matches any non-empty segment. The matched segments are then set
+// as path values of r, see http.Request.PathValue.
+func matchPath(r *http.Request, pattern string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(r.URL.Path, "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") {
+			if pathSegments[i] == "" {
+				return false
+			}
+		} else if segment != pathSegments[i] {
+			return false
+		}
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") {
+			r.SetPathValue(strings.Trim(segment, "{}"), pathSegments[i])
+		}
+	}
+	return true
+}
+
+func (srv *UserApi) handlerCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params CreateParams
+
+	params.Login = r.FormValue("login")
+	if params.Login == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "login must me not empty")
+		return
+	}
+	if len([]rune(params.Login)) < 3 {
+		writeErrorResponse(w, http.StatusBadRequest, "login len must be >= 3")
+		return
+	}
+
+	params.Class = r.FormValue("class")
+	if params.Class == "" {
+		params.Class = "warrior"
+	}
+	switch params.Class {
+	case "warrior", "sorcerer":
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "class must be one of [warrior, sorcerer]")
+		return
+	}
+
+	if raw := r.FormValue("level"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "level must be int")
+			return
+		}
+		params.Level = value
+	}
+	if params.Level < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "level must be >= 1")
+		return
+	}
+	if params.Level > 50 {
+		writeErrorResponse(w, http.StatusBadRequest, "level must be <= 50")
+		return
+	}
+
+	res, err := srv.Create(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *UserApi) handlerProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params ProfileParams
+
+	if raw := r.FormValue("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "limit must be int")
+			return
+		}
+		params.Limit = value
+	} else {
+		params.Limit = 10
+	}
+	if params.Limit < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "limit must be >= 1")
+		return
+	}
+	if params.Limit > 100 {
+		writeErrorResponse(w, http.StatusBadRequest, "limit must be <= 100")
+		return
+	}
+
+	params.Login = r.FormValue("user")
+	if params.Login == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "user must me not empty")
+		return
+	}
+
+	res, err := srv.Profile(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *UserApi) handlerLevel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params LevelParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+	if params.ID < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "id must be >= 1")
+		return
+	}
+
+	if raw := r.PathValue("level"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "level must be int")
+			return
+		}
+		params.Level = value
+	}
+	if params.Level > 50 {
+		writeErrorResponse(w, http.StatusBadRequest, "level must be <= 50")
+		return
+	}
+
+	res, err := srv.Level(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *UserApi) handlerGet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params GetParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	res, err := srv.Get(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *UserApi) handlerUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params UpdateParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Login = r.FormValue("login")
+	if params.Login == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "login must me not empty")
+		return
+	}
+
+	res, err := srv.Update(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *UserApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/user/create":
+		switch r.Method {
+		case "POST":
+			srv.handlerCreate(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/user/profile":
+		srv.handlerProfile(w, r)
+	case matchPath(r, "/user/{id}/level/{level}"):
+		srv.handlerLevel(w, r)
+	case matchPath(r, "/user/{id}"):
+		switch r.Method {
+		case "GET":
+			srv.handlerGet(w, r)
+		case "PUT", "PATCH":
+			srv.handlerUpdate(w, r)
+		default:
+			w.Header().Set("Allow", "GET, PUT, PATCH")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *TokenApi) handlerWhoami(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params WhoamiParams
+
+	res, err := srv.Whoami(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *TokenApi) handlerBan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	if !principal.HasRole("admin", "moderator") {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params BanParams
+
+	params.Login = r.FormValue("login")
+	if params.Login == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "login must me not empty")
+		return
+	}
+
+	res, err := srv.Ban(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *TokenApi) handlerSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.AuthenticateSchemes(srv, r, "bearer", "basic", "query")
+	if err != nil {
+		serve.Challenge(w, srv, "bearer", "basic", "query")
+		writeErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params WhoamiParams
+
+	res, err := srv.Session(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *TokenApi) handlerClaims(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.AuthenticateSchemes(srv, r, "jwt")
+	if err != nil {
+		serve.Challenge(w, srv, "jwt")
+		writeErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if !principal.HasRole("admin") {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params WhoamiParams
+
+	res, err := srv.Claims(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *TokenApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/whoami":
+		srv.handlerWhoami(w, r)
+	case r.URL.Path == "/ban":
+		switch r.Method {
+		case "POST":
+			srv.handlerBan(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/session":
+		srv.handlerSession(w, r)
+	case r.URL.Path == "/claims":
+		srv.handlerClaims(w, r)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *Greeter) handlerGreet(w http.ResponseWriter, r *http.Request) {
+	var params GreetParams
+
+	params.Name = r.FormValue("name")
+	if params.Name == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "name must me not empty")
+		return
+	}
+
+	res, err := srv.Greet(params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *Greeter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/greet":
+		srv.handlerGreet(w, r)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+// VersionHandler serves Version.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	res, err := Version()
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+// EchoHandler serves Echo.
+func EchoHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(nil, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+	if !serve.AllowRequest(ctx, w, r, nil, "Echo", serve.Limit{Requests: 100, Per: 1 * time.Second}) {
+		writeErrorResponse(w, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+
+	var params EchoParams
+
+	params.Text = r.FormValue("text")
+	if params.Text == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "text must me not empty")
+		return
+	}
+
+	if raw := r.PathValue("times"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "times must be int")
+			return
+		}
+		params.Times = value
+	}
+	if params.Times < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "times must be >= 1")
+		return
+	}
+	if params.Times > 3 {
+		writeErrorResponse(w, http.StatusBadRequest, "times must be <= 3")
+		return
+	}
+
+	res, err := Echo(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+// Router routes the requests to the handlers of the annotated
+// functions of the package.
+func Router() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/version":
+			VersionHandler(w, r)
+		case matchPath(r, "/echo/{times}"):
+			switch r.Method {
+			case "POST":
+				EchoHandler(w, r)
+			default:
+				w.Header().Set("Allow", "POST")
+				writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+			}
+		default:
+			writeErrorResponse(w, http.StatusNotFound, "unknown method")
+		}
+	})
+}
+
+func (srv *LimitApi) handlerSleep(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params SleepParams
+
+	if raw := r.FormValue("ms"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "ms must be int")
+			return
+		}
+		params.Ms = value
+	}
+	if params.Ms < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "ms must be >= 0")
+		return
+	}
+
+	release, ok := serve.Enter(ctx, srv, "LimitApi.Sleep", 4)
+	if !ok {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "too many requests in flight")
+		return
+	}
+
+	res, err := serve.CallTimeout(ctx, 50*time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		// the slot is held until the call returns, not until it times out
+		defer release()
+		return srv.Sleep(ctx, params)
+	})
+	if err == serve.ErrTimeout {
+		writeErrorResponse(w, http.StatusGatewayTimeout, "timeout")
+		return
+	}
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *LimitApi) handlerWait(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params SleepParams
+
+	if raw := r.FormValue("ms"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "ms must be int")
+			return
+		}
+		params.Ms = value
+	}
+	if params.Ms < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "ms must be >= 0")
+		return
+	}
+
+	res, err := serve.CallTimeout(ctx, 50*time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		return srv.Wait(ctx, params)
+	})
+	if err == serve.ErrTimeout {
+		writeErrorResponse(w, http.StatusGatewayTimeout, "timeout")
+		return
+	}
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *LimitApi) handlerLimited(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !serve.AllowRequest(ctx, w, r, srv, "LimitApi.Limited", serve.Limit{Requests: 2, Per: 1 * time.Minute}) {
+		writeErrorResponse(w, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+
+	var params SleepParams
+
+	if raw := r.FormValue("ms"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "ms must be int")
+			return
+		}
+		params.Ms = value
+	}
+	if params.Ms < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "ms must be >= 0")
+		return
+	}
+
+	res, err := srv.Limited(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *LimitApi) handlerHeavy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params SleepParams
+
+	if raw := r.FormValue("ms"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "ms must be int")
+			return
+		}
+		params.Ms = value
+	}
+	if params.Ms < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "ms must be >= 0")
+		return
+	}
+
+	release, ok := serve.Enter(ctx, srv, "LimitApi.Heavy", 1)
+	if !ok {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "too many requests in flight")
+		return
+	}
+	defer release()
+
+	res, err := srv.Heavy(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *LimitApi) handlerCrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	_, err := serve.CallTimeout(ctx, 1*time.Second, func(ctx context.Context) (interface{}, error) {
+		return nil, srv.Crash(ctx)
+	})
+	if err == serve.ErrTimeout {
+		writeErrorResponse(w, http.StatusGatewayTimeout, "timeout")
+		return
+	}
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func (srv *LimitApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/sleep":
+		srv.handlerSleep(w, r)
+	case r.URL.Path == "/wait":
+		srv.handlerWait(w, r)
+	case r.URL.Path == "/limited":
+		srv.handlerLimited(w, r)
+	case r.URL.Path == "/heavy":
+		srv.handlerHeavy(w, r)
+	case r.URL.Path == "/crash":
+		srv.handlerCrash(w, r)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *ScalarApi) handlerScalars(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params ScalarParams
+
+	if raw := r.FormValue("active"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "active must be bool")
+			return
+		}
+		params.Active = value
+	}
+
+	if raw := r.FormValue("small"); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 8)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "small must be int8")
+			return
+		}
+		params.Small = int8(value)
+	}
+	if params.Small < -5 {
+		writeErrorResponse(w, http.StatusBadRequest, "small must be >= -5")
+		return
+	}
+	if params.Small > 5 {
+		writeErrorResponse(w, http.StatusBadRequest, "small must be <= 5")
+		return
+	}
+
+	if raw := r.FormValue("count"); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 16)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "count must be int16")
+			return
+		}
+		params.Count = int16(value)
+	} else {
+		params.Count = 3
+	}
+
+	if raw := r.FormValue("offset"); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "offset must be int32")
+			return
+		}
+		params.Offset = int32(value)
+	}
+
+	if raw := r.FormValue("big"); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "big must be int64")
+			return
+		}
+		params.Big = value
+	}
+	if params.Big > 9000000000 {
+		writeErrorResponse(w, http.StatusBadRequest, "big must be <= 9000000000")
+		return
+	}
+
+	if raw := r.FormValue("port"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 16)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "port must be uint16")
+			return
+		}
+		params.Port = uint16(value)
+	} else {
+		params.Port = 8080
+	}
+	if params.Port < 1024 {
+		writeErrorResponse(w, http.StatusBadRequest, "port must be >= 1024")
+		return
+	}
+
+	if raw := r.FormValue("total"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "total must be uint")
+			return
+		}
+		params.Total = uint(value)
+	}
+
+	if raw := r.FormValue("id"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be uint64")
+			return
+		}
+		params.ID = value
+	}
+
+	if raw := r.FormValue("ratio"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 32)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "ratio must be float32")
+			return
+		}
+		params.Ratio = float32(value)
+	}
+	if params.Ratio < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "ratio must be >= 0")
+		return
+	}
+	if params.Ratio > 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "ratio must be <= 1")
+		return
+	}
+
+	if raw := r.FormValue("score"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "score must be float64")
+			return
+		}
+		params.Score = value
+	} else {
+		params.Score = 0.5
+	}
+
+	if raw := r.FormValue("wait"); raw != "" {
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "wait must be duration")
+			return
+		}
+		params.Wait = value
+	} else {
+		params.Wait = 10 * time.Second
+	}
+	if params.Wait < 1*time.Second {
+		writeErrorResponse(w, http.StatusBadRequest, "wait must be >= 1s")
+		return
+	}
+	if params.Wait > 1*time.Minute {
+		writeErrorResponse(w, http.StatusBadRequest, "wait must be <= 1m")
+		return
+	}
+
+	if raw := r.FormValue("since"); raw != "" {
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "since must be time like 2006-01-02T15:04:05Z07:00")
+			return
+		}
+		params.Since = value
+	}
+
+	if raw := r.FormValue("day"); raw != "" {
+		value, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "day must be time like 2006-01-02")
+			return
+		}
+		params.Day = Day(value)
+	}
+
+	if raw := r.FormValue("clock"); raw != "" {
+		value, err := time.Parse("15:04", raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "clock must be time like 15:04")
+			return
+		}
+		params.Clock = value
+	}
+
+	if raw := r.FormValue("rank"); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 8)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "rank must be int8")
+			return
+		}
+		params.Rank = Rank(value)
+	} else {
+		params.Rank = 1
+	}
+	switch params.Rank {
+	case 1, 2, 3:
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "rank must be one of [1, 2, 3]")
+		return
+	}
+
+	params.Tag = Tag(r.FormValue("tag"))
+	if params.Tag == "" {
+		params.Tag = "new"
+	}
+	switch params.Tag {
+	case "new", "old":
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "tag must be one of [new, old]")
+		return
+	}
+
+	if raw := r.FormValue("deadline"); raw != "" {
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "deadline must be duration")
+			return
+		}
+		params.Deadline = Deadline(value)
+	}
+	if params.Deadline > Deadline(5*time.Second) {
+		writeErrorResponse(w, http.StatusBadRequest, "deadline must be <= 5s")
+		return
+	}
+
+	res, err := srv.Scalars(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ScalarApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/scalars":
+		srv.handlerScalars(w, r)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *ServiceApi) handlerItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params ItemParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Name = r.FormValue("name")
+
+	res, err := srv.Item(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ServiceApi) handlerSaveItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	bodyValue, err := serve.BodyValues(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params ItemParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Name = bodyValue("name")
+
+	res, err := srv.SaveItem(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusCreated, res)
+}
+
+func (srv *ServiceApi) handlerIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params WhoamiParams
+
+	res, err := srv.Index(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ServiceApi) handlerImportItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	bodyValue, err := serve.JSONBody(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params ItemParams
+
+	if raw := bodyValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Name = bodyValue("name")
+
+	res, err := srv.ImportItem(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusCreated, res)
+}
+
+func (srv *ServiceApi) handlerDeleteItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params ItemParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Name = r.FormValue("name")
+
+	res, err := srv.DeleteItem(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusNoContent, res)
+}
+
+func (srv *ServiceApi) handlerTouch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	_, err = serve.JSONBody(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params TouchParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	res, err := srv.Touch(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ServiceApi) handlerReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	_, err := serve.JSONBody(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = srv.Reset(ctx)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func (srv *ServiceApi) handlerRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params JobParams
+
+	params.Name = r.FormValue("name")
+
+	res, err := srv.Run(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, serve.Status(res, http.StatusOK), res)
+}
+
+func (srv *ServiceApi) handlerSetAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params AvatarParams
+
+	params.Login = r.FormValue("login")
+	if params.Login == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "login must me not empty")
+		return
+	}
+
+	if upload, err := serve.FormFile(r, "avatar", 1024, "image/png", "image/gif"); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	} else if upload != nil {
+		params.Avatar = *upload
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "avatar must me not empty")
+		return
+	}
+
+	if upload, err := serve.FormFile(r, "note", 16); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	} else if upload != nil {
+		params.Note = upload.Data
+	}
+
+	res, err := srv.SetAvatar(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ServiceApi) handlerSetNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	_, err = serve.BodyValues(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params NoteParams
+
+	if upload, err := serve.FormFile(r, "note", 16); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	} else if upload != nil {
+		params.Note = upload.Data
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "note must me not empty")
+		return
+	}
+
+	res, err := srv.SetNote(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *ServiceApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/svc" && !strings.HasPrefix(r.URL.Path, "/svc/") {
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+		return
+	}
+	switch {
+	case r.URL.Path == "/svc":
+		switch r.Method {
+		case "GET":
+			srv.handlerIndex(w, r)
+		default:
+			w.Header().Set("Allow", "GET")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/svc/items":
+		switch r.Method {
+		case "POST":
+			srv.handlerImportItem(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/svc/reset":
+		switch r.Method {
+		case "POST":
+			srv.handlerReset(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/svc/jobs":
+		switch r.Method {
+		case "POST":
+			srv.handlerRun(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/svc/avatar":
+		switch r.Method {
+		case "POST":
+			srv.handlerSetAvatar(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case r.URL.Path == "/svc/avatar/note":
+		switch r.Method {
+		case "POST":
+			srv.handlerSetNote(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case matchPath(r, "/svc/items/{id}"):
+		switch r.Method {
+		case "GET":
+			srv.handlerItem(w, r)
+		case "POST":
+			srv.handlerSaveItem(w, r)
+		case "DELETE":
+			srv.handlerDeleteItem(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	case matchPath(r, "/svc/items/{id}/touch"):
+		switch r.Method {
+		case "POST":
+			srv.handlerTouch(w, r)
+		default:
+			w.Header().Set("Allow", "POST")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *CatalogApi) handlerPing(w http.ResponseWriter, r *http.Request) {
+	err := srv.Ping()
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func (srv *CatalogApi) handlerHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	res, err := srv.Health(ctx)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *CatalogApi) handlerList(w http.ResponseWriter, r *http.Request) {
+	var params ListParams
+
+	if raw := r.FormValue("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "limit must be int")
+			return
+		}
+		params.Limit = value
+	} else {
+		params.Limit = 2
+	}
+	if params.Limit < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "limit must be >= 1")
+		return
+	}
+
+	res, err := srv.List(params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusOK, res)
+}
+
+func (srv *CatalogApi) handlerForget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	principal, err := serve.Authenticate(srv, r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+	ctx = serve.WithPrincipal(ctx, principal)
+
+	var params ItemParams
+
+	if raw := r.PathValue("id"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "id must be int")
+			return
+		}
+		params.ID = value
+	} else {
+		writeErrorResponse(w, http.StatusBadRequest, "id must me not empty")
+		return
+	}
+
+	params.Name = r.FormValue("name")
+
+	_, err = serve.CallTimeout(ctx, 1*time.Second, func(ctx context.Context) (interface{}, error) {
+		return nil, srv.Forget(ctx, params)
+	})
+	if err == serve.ErrTimeout {
+		writeErrorResponse(w, http.StatusGatewayTimeout, "timeout")
+		return
+	}
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	writeResponse(w, http.StatusNoContent, nil)
+}
+
+func (srv *CatalogApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/ping":
+		srv.handlerPing(w, r)
+	case r.URL.Path == "/health":
+		srv.handlerHealth(w, r)
+	case r.URL.Path == "/catalog":
+		srv.handlerList(w, r)
+	case matchPath(r, "/catalog/{id}"):
+		switch r.Method {
+		case "DELETE":
+			srv.handlerForget(w, r)
+		default:
+			w.Header().Set("Allow", "DELETE")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "bad method")
+		}
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
+
+func (srv *EventApi) handlerEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params EventsParams
+
+	if raw := r.FormValue("count"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "count must be int")
+			return
+		}
+		params.Count = value
+	}
+	if params.Count < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "count must be >= 0")
+		return
+	}
+
+	res, err := srv.Events(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	serve.Stream(ctx, w, r, "auto", http.StatusOK, res)
+}
+
+func (srv *EventApi) handlerSSE(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var params EventsParams
+
+	if raw := r.FormValue("count"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "count must be int")
+			return
+		}
+		params.Count = value
+	}
+	if params.Count < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "count must be >= 0")
+		return
+	}
+
+	res, err := srv.SSE(ctx, params)
+	if err != nil {
+		if apiErr, ok := err.(ApiError); ok {
+			writeErrorResponse(w, apiErr.HTTPStatus, apiErr.Err.Error())
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	serve.Stream(ctx, w, r, "sse", http.StatusOK, res)
+}
+
+func (srv *EventApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/events":
+		srv.handlerEvents(w, r)
+	case r.URL.Path == "/events/sse":
+		srv.handlerSSE(w, r)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "unknown method")
+	}
+}
@@ -0,0 +1,216 @@
+package testapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Case struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Status int
+	Result interface{}
+}
+
+// CR is an expected JSON response.
+type CR map[string]interface{}
+
+var authHeader = http.Header{"X-Auth": {"100500"}}
+
+func TestUserApi(t *testing.T) {
+	ts := httptest.NewServer(&UserApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/user/profile",
+			Query:  "user=rvasily",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"login": "rvasily", "level": 0}},
+		},
+		{
+			Path:   "/user/profile",
+			Query:  "user=nobody",
+			Status: http.StatusNotFound,
+			Result: CR{"error": "user not exist"},
+		},
+		{
+			Path:   "/user/profile",
+			Query:  "user=rvasily&limit=0",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "limit must be >= 1"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/user/create",
+			Query:  "login=rvasily&level=10",
+			Header: authHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"login": "rvasily", "level": 10}},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/user/create",
+			Query:  "login=rvasily&level=10",
+			Status: http.StatusForbidden,
+			Result: CR{"error": "unauthorized"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/user/create",
+			Query:  "login=rvasily&class=rouge",
+			Header: authHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "class must be one of [warrior, sorcerer]"},
+		},
+		{
+			Path:   "/unknown",
+			Status: http.StatusNotFound,
+			Result: CR{"error": "unknown method"},
+		},
+	})
+}
+
+func TestPathParams(t *testing.T) {
+	ts := httptest.NewServer(&UserApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/user/42/level/7",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 42, "login": "", "level": 7}},
+		},
+		{
+			Path:   "/user/0/level/7",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "id must be >= 1"},
+		},
+		{
+			Path:   "/user/42/level/high",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "level must be int"},
+		},
+		{
+			Path:   "/user/42/level/51",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "level must be <= 50"},
+		},
+		{
+			// path params are not taken from the query
+			Path:   "/user//level/7",
+			Query:  "id=42",
+			Status: http.StatusNotFound,
+			Result: CR{"error": "unknown method"},
+		},
+	})
+}
+
+func TestMethodDispatch(t *testing.T) {
+	ts := httptest.NewServer(&UserApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Method: http.MethodGet,
+			Path:   "/user/42",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 42, "login": "getter", "level": 0}},
+		},
+		{
+			Method: http.MethodPut,
+			Path:   "/user/42",
+			Query:  "login=putter",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 42, "login": "putter", "level": 0}},
+		},
+		{
+			Method: http.MethodPatch,
+			Path:   "/user/42",
+			Query:  "login=patcher",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 42, "login": "patcher", "level": 0}},
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/user/42",
+			Status: http.StatusMethodNotAllowed,
+			Result: CR{"error": "bad method"},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/user/create",
+			Header: authHeader,
+			Status: http.StatusMethodNotAllowed,
+			Result: CR{"error": "bad method"},
+		},
+	})
+
+	for path, allow := range map[string]string{
+		"/user/42":     "GET, PUT, PATCH",
+		"/user/create": "POST",
+	} {
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Allow"); got != allow {
+			t.Errorf("%s: expected Allow %q, got %q", path, allow, got)
+		}
+	}
+}
+
+func runTests(t *testing.T, ts *httptest.Server, cases []Case) {
+	t.Helper()
+	for idx, item := range cases {
+		caseName := fmt.Sprintf("case %d: [%s] %s %s", idx, item.Method, item.Path, item.Query)
+
+		var req *http.Request
+		var err error
+		if item.Method == http.MethodPost || item.Method == http.MethodPut || item.Method == http.MethodPatch {
+			req, err = http.NewRequest(item.Method, ts.URL+item.Path, strings.NewReader(item.Query))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req, err = http.NewRequest(item.Method, ts.URL+item.Path+"?"+item.Query, nil)
+		}
+		if err != nil {
+			t.Fatalf("[%s] %v", caseName, err)
+		}
+		for name, values := range item.Header {
+			req.Header[name] = values
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("[%s] request error: %v", caseName, err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%s] expected http status %v, got %v: %s", caseName, item.Status, resp.StatusCode, body)
+			continue
+		}
+		var result, expected interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Errorf("[%s] cant unpack json: %v", caseName, err)
+			continue
+		}
+		data, _ := json.Marshal(item.Result)
+		json.Unmarshal(data, &expected)
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("[%s] results not match\nGot: %#v\nExpected: %#v", caseName, result, expected)
+		}
+	}
+}
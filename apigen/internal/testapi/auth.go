@@ -0,0 +1,78 @@
+package testapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+)
+
+// TokenApi authenticates with its own token store instead of the default
+// X-Auth check.
+type TokenApi struct {
+	Tokens map[string]serve.Principal
+}
+
+type tokenStore map[string]serve.Principal
+
+func (tokens tokenStore) Lookup(token string) (*serve.Principal, bool) {
+	principal, ok := tokens[token]
+	if !ok {
+		return nil, false
+	}
+	return &principal, true
+}
+
+func (srv *TokenApi) Authenticator() serve.Authenticator {
+	return serve.HeaderToken{Header: "X-Token", Store: tokenStore(srv.Tokens)}
+}
+
+func (srv *TokenApi) AuthSchemes() serve.Schemes {
+	return serve.Schemes{
+		serve.SchemeBearer: serve.Bearer{Realm: "testapi", Store: tokenStore(srv.Tokens)},
+		serve.SchemeBasic:  serve.Basic{Realm: "testapi", Store: serve.StaticPasswords{"rvasily": "qwerty"}},
+		serve.SchemeJWT:    jwtVerifier,
+	}
+}
+
+var jwtVerifier = serve.JWT{Realm: "testapi", Secret: []byte("testapi"), Audience: "testapi"}
+
+type WhoamiParams struct{}
+
+type Whoami struct {
+	ID string `json:"id"`
+}
+
+// apigen:api {"url": "/whoami", "auth": true}
+func (srv *TokenApi) Whoami(ctx context.Context, in WhoamiParams) (*Whoami, error) {
+	principal, ok := serve.PrincipalFrom(ctx)
+	if !ok {
+		return nil, ApiError{http.StatusInternalServerError, errors.New("no principal")}
+	}
+	return &Whoami{ID: principal.ID}, nil
+}
+
+type BanParams struct {
+	Login string `apivalidator:"required"`
+}
+
+// apigen:api {"url": "/ban", "method": "POST", "roles": ["admin", "moderator"]}
+func (srv *TokenApi) Ban(ctx context.Context, in BanParams) (*Whoami, error) {
+	return &Whoami{ID: in.Login}, nil
+}
+
+// apigen:api {"url": "/session", "auth_schemes": ["bearer", "basic", "query"]}
+func (srv *TokenApi) Session(ctx context.Context, in WhoamiParams) (*Whoami, error) {
+	return srv.Whoami(ctx, in)
+}
+
+// apigen:api {"url": "/claims", "auth_schemes": ["jwt"], "roles": ["admin"]}
+func (srv *TokenApi) Claims(ctx context.Context, in WhoamiParams) (*Whoami, error) {
+	claims, ok := serve.ClaimsFrom(ctx)
+	if !ok {
+		return nil, ApiError{http.StatusInternalServerError, errors.New("no principal")}
+	}
+	sub, _ := claims["sub"].(string)
+	return &Whoami{ID: sub}, nil
+}
@@ -0,0 +1,40 @@
+package testapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Greeter is a plain value, its methods have value receivers.
+type Greeter struct {
+	Greeting string
+}
+
+type GreetParams struct {
+	Name string `apivalidator:"required"`
+}
+
+// apigen:api {"url": "/greet"}
+func (g Greeter) Greet(in GreetParams) (string, error) {
+	return g.Greeting + ", " + in.Name, nil
+}
+
+// apigen:api {"url": "/version"}
+func Version() (string, error) {
+	return "1.0", nil
+}
+
+type EchoParams struct {
+	Text  string `apivalidator:"required"`
+	Times int    `apivalidator:"min=1,max=3"`
+}
+
+// apigen:api {"url": "/echo/{times}", "method": "POST", "auth": true, "rate_limit": "100/s"}
+func Echo(ctx context.Context, in EchoParams) (string, error) {
+	if strings.Contains(in.Text, "!") {
+		return "", ApiError{http.StatusBadRequest, errors.New("no shouting")}
+	}
+	return strings.Repeat(in.Text, in.Times), nil
+}
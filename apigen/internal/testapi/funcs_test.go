@@ -0,0 +1,91 @@
+package testapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValueReceiver(t *testing.T) {
+	ts := httptest.NewServer(&Greeter{Greeting: "hello"})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/greet",
+			Query:  "name=gopher",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": "hello, gopher"},
+		},
+		{
+			Path:   "/greet",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "name must me not empty"},
+		},
+	})
+}
+
+func TestRouter(t *testing.T) {
+	ts := httptest.NewServer(Router())
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/version",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": "1.0"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/echo/2",
+			Query:  "text=ab",
+			Header: authHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": "abab"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/echo/2",
+			Query:  "text=ab!",
+			Header: authHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "no shouting"},
+		},
+		{
+			// package functions get the default authenticator
+			Method: http.MethodPost,
+			Path:   "/echo/2",
+			Query:  "text=ab",
+			Status: http.StatusForbidden,
+			Result: CR{"error": "unauthorized"},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/echo/2",
+			Status: http.StatusMethodNotAllowed,
+			Result: CR{"error": "bad method"},
+		},
+		{
+			Path:   "/greet",
+			Status: http.StatusNotFound,
+			Result: CR{"error": "unknown method"},
+		},
+	})
+}
+
+func TestFuncHandler(t *testing.T) {
+	// the handlers work on their own, without the router
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/version", VersionHandler)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Method: http.MethodGet,
+			Path:   "/v1/version",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": "1.0"},
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package testapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+)
+
+// LimitApi has the endpoints whose calls are limited in time or number.
+type LimitApi struct {
+	Limits serve.Limiter
+	Heads  *serve.Bulkheads
+	// Gate holds Heavy calls until it is closed
+	Gate chan struct{}
+}
+
+func (srv *LimitApi) Limiter() serve.Limiter {
+	if srv.Limits == nil {
+		return serve.DefaultLimiter
+	}
+	return srv.Limits
+}
+
+func (srv *LimitApi) Bulkheads() *serve.Bulkheads {
+	if srv.Heads == nil {
+		return serve.DefaultBulkheads
+	}
+	return srv.Heads
+}
+
+type SleepParams struct {
+	Ms int `apivalidator:"min=0"`
+}
+
+type Slept struct {
+	Ms int `json:"ms"`
+}
+
+// Sleep ignores ctx on purpose, the timeout must hold anyway.
+// apigen:api {"url": "/sleep", "timeout": "50ms", "max_in_flight": 4}
+func (srv *LimitApi) Sleep(ctx context.Context, in SleepParams) (*Slept, error) {
+	time.Sleep(time.Duration(in.Ms) * time.Millisecond)
+	return &Slept{Ms: in.Ms}, nil
+}
+
+// apigen:api {"url": "/wait", "timeout": "50ms"}
+func (srv *LimitApi) Wait(ctx context.Context, in SleepParams) (*Slept, error) {
+	select {
+	case <-time.After(time.Duration(in.Ms) * time.Millisecond):
+		return &Slept{Ms: in.Ms}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// apigen:api {"url": "/limited", "rate_limit": "2/m"}
+func (srv *LimitApi) Limited(ctx context.Context, in SleepParams) (*Slept, error) {
+	return &Slept{Ms: in.Ms}, nil
+}
+
+// apigen:api {"url": "/heavy", "max_in_flight": 1}
+func (srv *LimitApi) Heavy(ctx context.Context, in SleepParams) (*Slept, error) {
+	<-srv.Gate
+	return &Slept{Ms: in.Ms}, nil
+}
+
+// apigen:api {"url": "/crash", "timeout": "1s"}
+func (srv *LimitApi) Crash(ctx context.Context) error {
+	panic("boom")
+}
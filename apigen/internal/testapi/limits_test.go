@@ -0,0 +1,125 @@
+package testapi
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/serve"
+)
+
+func TestTimeout(t *testing.T) {
+	ts := httptest.NewServer(&LimitApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/sleep",
+			Query:  "ms=1",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"ms": 1}},
+		},
+		{
+			Path:   "/sleep",
+			Query:  "ms=500",
+			Status: http.StatusGatewayTimeout,
+			Result: CR{"error": "timeout"},
+		},
+		{
+			// the method returns ctx.Err() itself
+			Path:   "/wait",
+			Query:  "ms=500",
+			Status: http.StatusGatewayTimeout,
+			Result: CR{"error": "timeout"},
+		},
+	})
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	ts := httptest.NewUnstartedServer(&LimitApi{})
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.Start()
+	defer ts.Close()
+
+	// net/http recovers the panic like that of any handler: the request
+	// fails, the server keeps serving
+	if resp, err := http.Get(ts.URL + "/crash"); err == nil {
+		resp.Body.Close()
+		t.Fatalf("expected the request to fail, got status %d", resp.StatusCode)
+	}
+	runTests(t, ts, []Case{
+		{
+			Path:   "/sleep",
+			Query:  "ms=1",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"ms": 1}},
+		},
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	buckets := serve.NewTokenBuckets()
+	buckets.Now = func() time.Time { return now }
+	ts := httptest.NewServer(&LimitApi{Limits: buckets})
+	defer ts.Close()
+
+	allowed := Case{
+		Path:   "/limited",
+		Status: http.StatusOK,
+		Result: CR{"error": "", "response": CR{"ms": 0}},
+	}
+	rejected := Case{
+		Path:   "/limited",
+		Status: http.StatusTooManyRequests,
+		Result: CR{"error": "too many requests"},
+	}
+	runTests(t, ts, []Case{allowed, allowed, rejected})
+
+	resp, err := http.Get(ts.URL + "/limited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After 30, got %q", got)
+	}
+
+	// a token is back every 30 seconds
+	now = now.Add(30 * time.Second)
+	runTests(t, ts, []Case{allowed, rejected})
+}
+
+func TestMaxInFlight(t *testing.T) {
+	api := &LimitApi{Heads: serve.NewBulkheads(10 * time.Millisecond), Gate: make(chan struct{})}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runTests(t, ts, []Case{{
+			Path:   "/heavy",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"ms": 0}},
+		}})
+	}()
+	for api.Heads.InFlight()["LimitApi.Heavy"] != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	runTests(t, ts, []Case{{
+		Path:   "/heavy",
+		Status: http.StatusServiceUnavailable,
+		Result: CR{"error": "too many requests in flight"},
+	}})
+
+	close(api.Gate)
+	<-done
+	if inFlight := api.Heads.InFlight()["LimitApi.Heavy"]; inFlight != 0 {
+		t.Errorf("expected no call in flight, got %d", inFlight)
+	}
+}
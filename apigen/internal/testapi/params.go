@@ -0,0 +1,32 @@
+package testapi
+
+type Paging struct {
+	Limit int `apivalidator:"min=1,max=100,default=10"`
+}
+
+type ProfileParams = profileParams
+
+type profileParams struct {
+	Paging
+	Login string `apivalidator:"required,paramname=user"`
+}
+
+type CreateParams struct {
+	Login string `apivalidator:"required,min=3"`
+	Class string `apivalidator:"enum=warrior|sorcerer,default=warrior"`
+	Level int    `apivalidator:"min=1,max=50"`
+}
+
+type LevelParams struct {
+	ID    int `apivalidator:"required,min=1"`
+	Level int `apivalidator:"max=50"`
+}
+
+type GetParams struct {
+	ID int `apivalidator:"required"`
+}
+
+type UpdateParams struct {
+	ID    int    `apivalidator:"required"`
+	Login string `apivalidator:"required"`
+}
@@ -0,0 +1,59 @@
+package testapi
+
+import (
+	"context"
+	"time"
+)
+
+// ScalarApi echoes a param of every scalar kind.
+type ScalarApi struct{}
+
+type (
+	Rank     int8
+	Tag      string
+	Deadline time.Duration
+	Day      time.Time
+)
+
+type ScalarParams struct {
+	Active   bool
+	Small    int8  `apivalidator:"min=-5,max=5"`
+	Count    int16 `apivalidator:"default=3"`
+	Offset   int32
+	Big      int64  `apivalidator:"max=9000000000"`
+	Port     uint16 `apivalidator:"min=1024,default=8080"`
+	Total    uint
+	ID       uint64
+	Ratio    float32       `apivalidator:"min=0,max=1"`
+	Score    float64       `apivalidator:"default=0.5"`
+	Wait     time.Duration `apivalidator:"min=1s,max=1m,default=10s"`
+	Since    time.Time
+	Day      Day       `apivalidator:"layout=DateOnly"`
+	Clock    time.Time `apivalidator:"layout=15:04"`
+	Rank     Rank      `apivalidator:"enum=1|2|3,default=1"`
+	Tag      Tag       `apivalidator:"enum=new|old,default=new"`
+	Deadline Deadline  `apivalidator:"max=5s"`
+}
+
+// apigen:api {"url": "/scalars"}
+func (srv *ScalarApi) Scalars(ctx context.Context, in ScalarParams) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"active":   in.Active,
+		"small":    in.Small,
+		"count":    in.Count,
+		"offset":   in.Offset,
+		"big":      in.Big,
+		"port":     in.Port,
+		"total":    in.Total,
+		"id":       in.ID,
+		"ratio":    in.Ratio,
+		"score":    in.Score,
+		"wait":     in.Wait.String(),
+		"since":    in.Since.Format(time.RFC3339),
+		"day":      time.Time(in.Day).Format(time.DateOnly),
+		"clock":    in.Clock.Format("15:04"),
+		"rank":     in.Rank,
+		"tag":      in.Tag,
+		"deadline": time.Duration(in.Deadline).String(),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package testapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestScalars(t *testing.T) {
+	ts := httptest.NewServer(&ScalarApi{})
+	defer ts.Close()
+
+	defaults := CR{
+		"active": false, "small": 0, "count": 3, "offset": 0, "big": 0, "port": 8080, "total": 0, "id": 0,
+		"ratio": 0, "score": 0.5, "wait": "10s", "since": "0001-01-01T00:00:00Z", "day": "0001-01-01",
+		"clock": "00:00", "rank": 1, "tag": "new", "deadline": "0s",
+	}
+	runTests(t, ts, []Case{
+		{
+			Path:   "/scalars",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": defaults},
+		},
+		{
+			Path: "/scalars",
+			Query: "active=true&small=-5&count=300&offset=-70000&big=9000000000&port=8080&total=7&id=18446744073709551615" +
+				"&ratio=0.25&score=-1.5&wait=1m&since=2024-02-29T12:00:00Z&day=2024-03-01&clock=09:30&rank=3&tag=old&deadline=2s",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{
+				"active": true, "small": -5, "count": 300, "offset": -70000, "big": 9000000000, "port": 8080, "total": 7,
+				"id": uint64(18446744073709551615), "ratio": 0.25, "score": -1.5, "wait": "1m0s", "since": "2024-02-29T12:00:00Z",
+				"day": "2024-03-01", "clock": "09:30", "rank": 3, "tag": "old", "deadline": "2s",
+			}},
+		},
+		{Path: "/scalars", Query: "active=yes", Status: http.StatusBadRequest, Result: CR{"error": "active must be bool"}},
+		{Path: "/scalars", Query: "small=6", Status: http.StatusBadRequest, Result: CR{"error": "small must be <= 5"}},
+		{Path: "/scalars", Query: "small=200", Status: http.StatusBadRequest, Result: CR{"error": "small must be int8"}},
+		{Path: "/scalars", Query: "count=40000", Status: http.StatusBadRequest, Result: CR{"error": "count must be int16"}},
+		{Path: "/scalars", Query: "big=9000000001", Status: http.StatusBadRequest, Result: CR{"error": "big must be <= 9000000000"}},
+		{Path: "/scalars", Query: "port=80", Status: http.StatusBadRequest, Result: CR{"error": "port must be >= 1024"}},
+		{Path: "/scalars", Query: "total=-1", Status: http.StatusBadRequest, Result: CR{"error": "total must be uint"}},
+		{Path: "/scalars", Query: "ratio=1.5", Status: http.StatusBadRequest, Result: CR{"error": "ratio must be <= 1"}},
+		{Path: "/scalars", Query: "score=high", Status: http.StatusBadRequest, Result: CR{"error": "score must be float64"}},
+		{Path: "/scalars", Query: "wait=500ms", Status: http.StatusBadRequest, Result: CR{"error": "wait must be >= 1s"}},
+		{Path: "/scalars", Query: "wait=soon", Status: http.StatusBadRequest, Result: CR{"error": "wait must be duration"}},
+		{Path: "/scalars", Query: "since=2024-02-29", Status: http.StatusBadRequest, Result: CR{"error": "since must be time like 2006-01-02T15:04:05Z07:00"}},
+		{Path: "/scalars", Query: "day=01.03.2024", Status: http.StatusBadRequest, Result: CR{"error": "day must be time like 2006-01-02"}},
+		{Path: "/scalars", Query: "clock=9am", Status: http.StatusBadRequest, Result: CR{"error": "clock must be time like 15:04"}},
+		{Path: "/scalars", Query: "rank=4", Status: http.StatusBadRequest, Result: CR{"error": "rank must be one of [1, 2, 3]"}},
+		{Path: "/scalars", Query: "tag=mid", Status: http.StatusBadRequest, Result: CR{"error": "tag must be one of [new, old]"}},
+		{Path: "/scalars", Query: "deadline=10s", Status: http.StatusBadRequest, Result: CR{"error": "deadline must be <= 5s"}},
+	})
+}
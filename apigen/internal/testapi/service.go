@@ -0,0 +1,82 @@
+package testapi
+
+import (
+	"context"
+	"net/http"
+)
+
+// ServiceApi serves its endpoints under /svc, all of them GET and
+// authenticated unless they say otherwise.
+// apigen:service {"prefix": "/svc", "auth": true, "method": "GET"}
+type ServiceApi struct{}
+
+type ItemParams struct {
+	ID   int `apivalidator:"required"`
+	Name string
+}
+
+type Item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// apigen:api {"url": "/items/{id}"}
+func (srv *ServiceApi) Item(ctx context.Context, in ItemParams) (*Item, error) {
+	return &Item{ID: in.ID, Name: "item"}, nil
+}
+
+// apigen:api {"url": "/items/{id}", "method": "POST", "status": 201, "body": "auto"}
+func (srv *ServiceApi) SaveItem(ctx context.Context, in ItemParams) (*Item, error) {
+	return &Item{ID: in.ID, Name: in.Name}, nil
+}
+
+// apigen:api {"url": "", "auth": false}
+func (srv *ServiceApi) Index(ctx context.Context, in WhoamiParams) (*Item, error) {
+	return &Item{Name: "index"}, nil
+}
+
+// apigen:api {"url": "/items", "method": "POST", "status": 201, "body": "json"}
+func (srv *ServiceApi) ImportItem(ctx context.Context, in ItemParams) (*Item, error) {
+	return &Item{ID: in.ID, Name: in.Name}, nil
+}
+
+// apigen:api {"url": "/items/{id}", "method": "DELETE", "status": 204}
+func (srv *ServiceApi) DeleteItem(ctx context.Context, in ItemParams) (*Item, error) {
+	return &Item{ID: in.ID}, nil
+}
+
+type TouchParams struct {
+	ID int `apivalidator:"required"`
+}
+
+// apigen:api {"url": "/items/{id}/touch", "method": "POST", "body": "json"}
+func (srv *ServiceApi) Touch(ctx context.Context, in TouchParams) (*Item, error) {
+	return &Item{ID: in.ID, Name: "touched"}, nil
+}
+
+// apigen:api {"url": "/reset", "method": "POST", "auth": false, "body": "json"}
+func (srv *ServiceApi) Reset(ctx context.Context) error {
+	return nil
+}
+
+type JobParams struct {
+	Name string
+}
+
+// Job is accepted to run later, unless it is done already.
+type Job struct {
+	Name string `json:"name"`
+	Done bool   `json:"done"`
+}
+
+func (job *Job) StatusCode() int {
+	if job.Done {
+		return 0
+	}
+	return http.StatusAccepted
+}
+
+// apigen:api {"url": "/jobs", "method": "POST"}
+func (srv *ServiceApi) Run(ctx context.Context, in JobParams) (*Job, error) {
+	return &Job{Name: in.Name, Done: in.Name == "noop"}, nil
+}
@@ -0,0 +1,184 @@
+package testapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService(t *testing.T) {
+	ts := httptest.NewServer(&ServiceApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Path:   "/svc/items/7",
+			Header: authHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 7, "name": "item"}},
+		},
+		{
+			// auth is inherited from the service
+			Path:   "/svc/items/7",
+			Status: http.StatusForbidden,
+			Result: CR{"error": "unauthorized"},
+		},
+		{
+			// and so is the method, unless overridden
+			Method: http.MethodPost,
+			Path:   "/svc/items/7",
+			Query:  "name=saved",
+			Header: authHeader,
+			Status: http.StatusCreated,
+			Result: CR{"error": "", "response": CR{"id": 7, "name": "saved"}},
+		},
+		{
+			Method: http.MethodPut,
+			Path:   "/svc/items/7",
+			Header: authHeader,
+			Status: http.StatusMethodNotAllowed,
+			Result: CR{"error": "bad method"},
+		},
+		{
+			Path:   "/svc",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 0, "name": "index"}},
+		},
+		{
+			Path:   "/items/7",
+			Header: authHeader,
+			Status: http.StatusNotFound,
+			Result: CR{"error": "unknown method"},
+		},
+	})
+}
+
+func TestStatus(t *testing.T) {
+	ts := httptest.NewServer(&ServiceApi{})
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/jobs",
+			Query:  "name=backup",
+			Header: authHeader,
+			Status: http.StatusAccepted,
+			Result: CR{"error": "", "response": CR{"name": "backup", "done": false}},
+		},
+		{
+			// StatusCode returns 0, the default status stays
+			Method: http.MethodPost,
+			Path:   "/svc/jobs",
+			Query:  "name=noop",
+			Header: authHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"name": "noop", "done": true}},
+		},
+	})
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/svc/items/7", nil)
+	req.Header.Set("X-Auth", "100500")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || len(body) != 0 {
+		t.Errorf("expected 204 without a body, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestJSONBody(t *testing.T) {
+	ts := httptest.NewServer(&ServiceApi{})
+	defer ts.Close()
+
+	jsonHeader := http.Header{"X-Auth": {"100500"}, "Content-Type": {"application/json"}}
+	runTests(t, ts, []Case{
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items",
+			Query:  `{"id": 5, "name": "imported"}`,
+			Header: jsonHeader,
+			Status: http.StatusCreated,
+			Result: CR{"error": "", "response": CR{"id": 5, "name": "imported"}},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items",
+			Query:  `{"id": "five"}`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "id must be int"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items",
+			Query:  `{"name": null}`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "id must me not empty"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items",
+			Query:  `{"id": 5, "name": {"first": "x"}}`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "bad json body: name must be a string, a number or a bool"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items",
+			Query:  `{"id": 5,`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "bad json body: unexpected EOF"},
+		},
+		{
+			// an auto endpoint takes JSON by Content-Type, the path param
+			// still comes from the path
+			Method: http.MethodPost,
+			Path:   "/svc/items/7",
+			Query:  `{"id": 5, "name": "renamed"}`,
+			Header: jsonHeader,
+			Status: http.StatusCreated,
+			Result: CR{"error": "", "response": CR{"id": 7, "name": "renamed"}},
+		},
+		{
+			// no field reads the body, it is still checked
+			Method: http.MethodPost,
+			Path:   "/svc/items/7/touch",
+			Query:  `{}`,
+			Header: jsonHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"id": 7, "name": "touched"}},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/items/7/touch",
+			Query:  `{"id": 5,`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "bad json body: unexpected EOF"},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/reset",
+			Query:  `{}`,
+			Header: jsonHeader,
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": nil},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/svc/reset",
+			Query:  `[`,
+			Header: jsonHeader,
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "bad json body: unexpected EOF"},
+		},
+	})
+}
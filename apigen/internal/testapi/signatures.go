@@ -0,0 +1,53 @@
+package testapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// CatalogApi has methods of every supported shape: without a context or a
+// param struct, returning only an error, a value or a slice.
+type CatalogApi struct {
+	mu        sync.Mutex
+	forgotten []int
+}
+
+// apigen:api {"url": "/ping"}
+func (srv *CatalogApi) Ping() error {
+	return nil
+}
+
+type Health struct {
+	Status string `json:"status"`
+}
+
+// apigen:api {"url": "/health"}
+func (srv *CatalogApi) Health(ctx context.Context) (Health, error) {
+	return Health{Status: "ok"}, ctx.Err()
+}
+
+type ListParams struct {
+	Limit int `apivalidator:"default=2,min=1"`
+}
+
+// apigen:api {"url": "/catalog"}
+func (srv *CatalogApi) List(in ListParams) ([]Item, error) {
+	items := []Item{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}, {ID: 3, Name: "three"}}
+	if in.Limit < len(items) {
+		items = items[:in.Limit]
+	}
+	return items, nil
+}
+
+// apigen:api {"url": "/catalog/{id}", "method": "DELETE", "auth": true, "status": 204, "timeout": "1s"}
+func (srv *CatalogApi) Forget(ctx context.Context, in ItemParams) error {
+	if in.ID == 1 {
+		return ApiError{http.StatusConflict, errors.New("item is in use")}
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.forgotten = append(srv.forgotten, in.ID)
+	return nil
+}
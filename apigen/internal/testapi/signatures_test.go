@@ -0,0 +1,66 @@
+package testapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSignatures(t *testing.T) {
+	api := &CatalogApi{}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	runTests(t, ts, []Case{
+		{
+			// the envelope is the same for methods returning only an error
+			Path:   "/ping",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": nil},
+		},
+		{
+			Path:   "/health",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": CR{"status": "ok"}},
+		},
+		{
+			Path:   "/catalog",
+			Status: http.StatusOK,
+			Result: CR{"error": "", "response": []CR{{"id": 1, "name": "one"}, {"id": 2, "name": "two"}}},
+		},
+		{
+			Path:   "/catalog",
+			Query:  "limit=0",
+			Status: http.StatusBadRequest,
+			Result: CR{"error": "limit must be >= 1"},
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/catalog/1",
+			Header: authHeader,
+			Status: http.StatusConflict,
+			Result: CR{"error": "item is in use"},
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/catalog/2",
+			Status: http.StatusForbidden,
+			Result: CR{"error": "unauthorized"},
+		},
+	})
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/catalog/2", nil)
+	req.Header = authHeader.Clone()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if !reflect.DeepEqual(api.forgotten, []int{2}) {
+		t.Errorf("expected item 2 forgotten, got %v", api.forgotten)
+	}
+}
@@ -0,0 +1,42 @@
+package testapi
+
+import "context"
+
+// EventApi streams events.
+type EventApi struct {
+	// Stopped gets a value when an Events stream stops sending
+	Stopped chan struct{}
+}
+
+type EventsParams struct {
+	// Count is the number of events, 0 for an endless stream
+	Count int `apivalidator:"min=0"`
+}
+
+type Event struct {
+	Seq int `json:"seq"`
+}
+
+// apigen:api {"url": "/events"}
+func (srv *EventApi) Events(ctx context.Context, in EventsParams) (<-chan Event, error) {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		if srv.Stopped != nil {
+			defer func() { srv.Stopped <- struct{}{} }()
+		}
+		for seq := 1; in.Count == 0 || seq <= in.Count; seq++ {
+			select {
+			case events <- Event{Seq: seq}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// apigen:api {"url": "/events/sse", "stream": "sse"}
+func (srv *EventApi) SSE(ctx context.Context, in EventsParams) (<-chan Event, error) {
+	return srv.Events(ctx, in)
+}
@@ -0,0 +1,74 @@
+package testapi
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	ts := httptest.NewServer(&EventApi{})
+	defer ts.Close()
+
+	cases := []struct {
+		path        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"/events?count=2", "", "application/x-ndjson", "{\"seq\":1}\n{\"seq\":2}\n"},
+		{"/events?count=2", "text/event-stream", "text/event-stream", "data: {\"seq\":1}\n\ndata: {\"seq\":2}\n\n"},
+		{"/events/sse?count=1", "", "text/event-stream", "data: {\"seq\":1}\n\n"},
+		{"/events?count=x", "", "", `{"error":"count must be int"}`},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+c.path, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if got := resp.Header.Get("Content-Type"); c.contentType != "" && got != c.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", c.path, c.contentType, got)
+		}
+		if string(body) != c.body {
+			t.Errorf("%s: expected body %q, got %q", c.path, c.body, body)
+		}
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	api := &EventApi{Stopped: make(chan struct{}, 1)}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every event is flushed as it comes, the endless stream can be read
+	lines := bufio.NewScanner(resp.Body)
+	for i := 0; i < 3; i++ {
+		if !lines.Scan() {
+			t.Fatalf("expected an event, got %v", lines.Err())
+		}
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-api.Stopped:
+	case <-time.After(time.Second):
+		t.Error("the stream did not stop after the client went away")
+	}
+}
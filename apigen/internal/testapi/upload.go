@@ -0,0 +1,40 @@
+package testapi
+
+import "context"
+
+// AvatarParams counts on the generated file to declare UploadedFile.
+type AvatarParams struct {
+	Login  string       `apivalidator:"required"`
+	Avatar UploadedFile `apivalidator:"required,maxsize=1024,mimetype=image/png|image/gif"`
+	Note   []byte       `apivalidator:"maxsize=16"`
+}
+
+type Avatar struct {
+	Login       string `json:"login"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Note        string `json:"note"`
+}
+
+// apigen:api {"url": "/avatar", "method": "POST"}
+func (srv *ServiceApi) SetAvatar(ctx context.Context, in AvatarParams) (*Avatar, error) {
+	return &Avatar{
+		Login:       in.Login,
+		Filename:    in.Avatar.Filename,
+		ContentType: in.Avatar.ContentType,
+		Size:        len(in.Avatar.Data),
+		Note:        string(in.Note),
+	}, nil
+}
+
+type NoteParams struct {
+	Note []byte `apivalidator:"required,maxsize=16"`
+}
+
+// SetNote reads no field from the body but uploads, which come from the
+// multipart form whatever its body setting.
+// apigen:api {"url": "/avatar/note", "method": "POST", "body": "auto"}
+func (srv *ServiceApi) SetNote(ctx context.Context, in NoteParams) (string, error) {
+	return string(in.Note), nil
+}
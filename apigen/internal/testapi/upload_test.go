@@ -0,0 +1,103 @@
+package testapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// png is the smallest content sniffed as image/png.
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("\x00", 24))
+
+func TestUpload(t *testing.T) {
+	ts := httptest.NewServer(&ServiceApi{})
+	defer ts.Close()
+
+	cases := []struct {
+		name   string
+		files  map[string][]byte
+		login  string
+		status int
+		result CR
+	}{
+		{
+			name:   "ok",
+			login:  "rvasily",
+			files:  map[string][]byte{"avatar": png, "note": []byte("hi")},
+			status: http.StatusOK,
+			result: CR{"error": "", "response": CR{"login": "rvasily", "filename": "avatar.bin", "content_type": "image/png", "size": len(png), "note": "hi"}},
+		},
+		{
+			name:   "missing",
+			login:  "rvasily",
+			status: http.StatusBadRequest,
+			result: CR{"error": "avatar must me not empty"},
+		},
+		{
+			name:   "too large",
+			login:  "rvasily",
+			files:  map[string][]byte{"avatar": append(png, make([]byte, 1024)...)},
+			status: http.StatusBadRequest,
+			result: CR{"error": "avatar size must be <= 1024"},
+		},
+		{
+			name:   "wrong type",
+			login:  "rvasily",
+			files:  map[string][]byte{"avatar": []byte("plain text")},
+			status: http.StatusBadRequest,
+			result: CR{"error": "avatar must be one of [image/png, image/gif]"},
+		},
+		{
+			name:   "note too large",
+			login:  "rvasily",
+			files:  map[string][]byte{"avatar": png, "note": []byte(strings.Repeat("x", 17))},
+			status: http.StatusBadRequest,
+			result: CR{"error": "note size must be <= 16"},
+		},
+		{
+			name:   "no login",
+			files:  map[string][]byte{"avatar": png},
+			status: http.StatusBadRequest,
+			result: CR{"error": "login must me not empty"},
+		},
+	}
+	for _, c := range cases {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		if c.login != "" {
+			form.WriteField("login", c.login)
+		}
+		for name, data := range c.files {
+			part, _ := form.CreateFormFile(name, name+".bin")
+			part.Write(data)
+		}
+		form.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/svc/avatar", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("X-Auth", "100500")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("%s: expected http status %d, got %d: %s", c.name, c.status, resp.StatusCode, data)
+			continue
+		}
+		var result, expected interface{}
+		json.Unmarshal(data, &result)
+		want, _ := json.Marshal(c.result)
+		json.Unmarshal(want, &expected)
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s: results not match\nGot: %#v\nExpected: %#v", c.name, result, expected)
+		}
+	}
+}
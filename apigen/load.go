@@ -0,0 +1,141 @@
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Package is a parsed and type-checked Go package: every non-test file that
+// the build constraints of the current platform select, in file name order.
+type Package struct {
+	Name  string
+	Dir   string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+
+	// uploadedFile is set when the package refers to an UploadedFile type
+	// it does not declare: the generated file declares it then, see
+	// uploadedFileSource.
+	uploadedFile bool
+	// imported are the packages loaded for their declarations, by import
+	// path, see importedPackage.
+	imported map[string]*Package
+}
+
+// uploadedFileSource is the declaration of UploadedFile the package is
+// type-checked with when it counts on the generated one.
+const uploadedFileSource = `package %s
+
+import serve %q
+
+type UploadedFile = serve.UploadedFile
+`
+
+// LoadPackage parses the package that lives in path. path may be either the
+// package directory or one of its files (the historical `codegen api.go ...`
+// form). Files listed in skip (usually the output file), as well as any file
+// generated by apigen, are left out so that a stale generated file never takes
+// part in generation.
+func LoadPackage(path string, skip ...string) (*Package, error) {
+	dir := path
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	skipped := make(map[string]bool)
+	for _, name := range skip {
+		if abs, err := filepath.Abs(name); err == nil {
+			skipped[abs] = true
+		}
+	}
+
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{
+		Name: bp.Name,
+		Dir:  dir,
+		Fset: token.NewFileSet(),
+	}
+	for _, name := range bp.GoFiles {
+		filename := filepath.Join(dir, name)
+		if abs, err := filepath.Abs(filename); err == nil && skipped[abs] {
+			continue
+		}
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if isGenerated(src) {
+			continue
+		}
+		file, err := parser.ParseFile(pkg.Fset, filename, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Files = append(pkg.Files, file)
+	}
+
+	pkg.Info = &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	files := pkg.Files
+	if refersToUndeclared(pkg.Files, "UploadedFile") {
+		pkg.uploadedFile = true
+		src := fmt.Sprintf(uploadedFileSource, pkg.Name, servePath)
+		file, err := parser.ParseFile(pkg.Fset, filepath.Join(dir, "apigen_uploadedfile.go"), src, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files[:len(files):len(files)], file)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(pkg.Fset, "source", nil),
+		// the package is checked without its generated file, so references
+		// to generated methods (ServeHTTP for one) are expected to fail
+		Error: func(error) {},
+	}
+	pkg.Types, _ = conf.Check(bp.ImportPath, pkg.Fset, files, pkg.Info)
+	return pkg, nil
+}
+
+// isGenerated reports whether src is a file apigen generated, which starts
+// with the line of generatedHeader.
+func isGenerated(src []byte) bool {
+	line, _, _ := strings.Cut(generatedHeader, "\n")
+	return bytes.HasPrefix(src, []byte(line+"\n"))
+}
+
+// refersToUndeclared reports whether files use the identifier name without
+// declaring it at the top level of any of them.
+func refersToUndeclared(files []*ast.File, name string) bool {
+	referred := false
+	for _, file := range files {
+		if file.Scope.Lookup(name) != nil {
+			return false
+		}
+		for _, ident := range file.Unresolved {
+			if ident.Name == name {
+				referred = true
+			}
+		}
+	}
+	return referred
+}
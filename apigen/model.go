@@ -0,0 +1,294 @@
+package apigen
+
+// The model is what Collect makes of a package and what the templates are
+// executed with. It is plain data, so it can also be dumped as JSON for tools
+// that want the API description without parsing Go.
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File is the model of one package: everything its generated file holds.
+type File struct {
+	Package string   `json:"package"`
+	Imports []Import `json:"-"`
+	APIs    []*API   `json:"apis"`
+	// UploadedFile is set when the package counts on the generated file to
+	// declare UploadedFile.
+	UploadedFile bool `json:"-"`
+}
+
+// PathParams reports whether any endpoint has {name} segments in its url.
+func (f *File) PathParams() bool {
+	for _, api := range f.APIs {
+		for _, endpoint := range api.Endpoints {
+			if len(endpoint.PathParams) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Import is an import of the generated file.
+type Import struct {
+	Name string // empty when it is the last element of Path
+	Path string
+}
+
+// API is a struct with at least one annotated method or, when Name is empty,
+// the annotated package-level functions.
+type API struct {
+	Name      string      `json:"name"`
+	Prefix    string      `json:"prefix,omitempty"` // of every url, from apigen:service
+	Endpoints []*Endpoint `json:"endpoints"`
+}
+
+// Route is a url of an API with the endpoints it leads to, told apart by the
+// HTTP method of the request. Urls that only differ in the names of their
+// {name} segments are the same route, the one of its first endpoint.
+type Route struct {
+	URL       string
+	Pattern   bool // URL has {name} segments
+	Endpoints []*Endpoint
+}
+
+// Routes groups the endpoints of api by url shape: plain urls first, then the
+// ones with {name} segments, each in the order of their first endpoint.
+func (api *API) Routes() []*Route {
+	var plain, patterns []*Route
+	byShape := make(map[string]*Route)
+	for _, endpoint := range api.Endpoints {
+		shape := urlShape(endpoint.URL)
+		route, ok := byShape[shape]
+		if !ok {
+			route = &Route{URL: endpoint.URL, Pattern: len(endpoint.PathParams) > 0}
+			byShape[shape] = route
+			if route.Pattern {
+				patterns = append(patterns, route)
+			} else {
+				plain = append(plain, route)
+			}
+		}
+		route.Endpoints = append(route.Endpoints, endpoint)
+	}
+	return append(plain, patterns...)
+}
+
+// Fallback is the endpoint of the route that accepts any method, if any.
+func (route *Route) Fallback() *Endpoint {
+	for _, endpoint := range route.Endpoints {
+		if len(endpoint.HTTPMethods) == 0 {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// Allow lists the methods the route accepts, for the Allow header.
+func (route *Route) Allow() string {
+	var methods []string
+	for _, endpoint := range route.Endpoints {
+		methods = append(methods, endpoint.HTTPMethods...)
+	}
+	return strings.Join(methods, ", ")
+}
+
+// Endpoint is an annotated method, the "handler" template data.
+type Endpoint struct {
+	pos token.Pos // of the annotation
+
+	Recv        string     `json:"receiver,omitempty"` // empty for a package-level function
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	Auth        bool       `json:"auth"`
+	Roles       []string   `json:"roles,omitempty"`         // any authenticated principal when empty
+	AuthSchemes []string   `json:"auth_schemes,omitempty"`  // the Authenticator of the API when empty
+	Timeout     string     `json:"timeout,omitempty"`       // of the method call, a time.Duration string
+	RateLimit   *RateLimit `json:"rate_limit,omitempty"`    // per client
+	MaxInFlight int        `json:"max_in_flight,omitempty"` // concurrent calls, no limit when 0
+	Status      int        `json:"status"`                  // of a successful call
+	Body        string     `json:"body,omitempty"`          // "json", "auto" (by Content-Type) or "" for a form
+	Stream      string     `json:"stream,omitempty"`        // "ndjson", "sse" or "auto" (by Accept) for a channel result
+	HTTPMethods []string   `json:"methods,omitempty"`       // any method when empty
+	PathParams  []string   `json:"path_params,omitempty"`   // {name} segments of URL
+	Context     bool       `json:"context"`                 // the method takes a context.Context
+	ParamType   string     `json:"param_type,omitempty"`    // no param struct when empty
+	Fields      []*Field   `json:"params"`
+	ResultType  string     `json:"result_type,omitempty"` // an error only when empty
+	// StatusFromResult is set when the result has a StatusCode() int
+	// method, which then chooses the status when it returns non-zero.
+	StatusFromResult bool `json:"status_from_result,omitempty"`
+}
+
+// RateLimit is the rate_limit of an endpoint.
+type RateLimit struct {
+	Requests int    `json:"requests"`
+	Per      string `json:"per"` // a time.Duration string
+}
+
+// routerName is the function generated to route the requests to the
+// package-level functions.
+const routerName = "Router"
+
+// FullName is the name of the endpoint method with its receiver type, the
+// name of the endpoint in diagnostics and in the keys of its limits.
+func (endpoint *Endpoint) FullName() string {
+	if endpoint.Recv == "" {
+		return endpoint.Name
+	}
+	return endpoint.Recv + "." + endpoint.Name
+}
+
+// FuncHandler is the name of the http.HandlerFunc generated for a
+// package-level function.
+func (endpoint *Endpoint) FuncHandler() string {
+	return endpoint.Name + "Handler"
+}
+
+// BodyFields lists the fields of the endpoint read from the request body.
+func (endpoint *Endpoint) BodyFields() []*Field {
+	var fields []*Field
+	for _, field := range endpoint.Fields {
+		if field.Source == "body" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// UsesContext reports whether the handler of the endpoint needs the context
+// of the request.
+func (endpoint *Endpoint) UsesContext() bool {
+	return endpoint.Context || endpoint.Auth || endpoint.RateLimit != nil || endpoint.MaxInFlight > 0 ||
+		endpoint.Timeout != "" || endpoint.Stream != ""
+}
+
+// Field is a params struct field with its apivalidator rules, the "field"
+// template data. Default, Min, Max and Enum are kept as written in the tag;
+// they are checked to be valid for Kind.
+type Field struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`             // see structField
+	Type      string   `json:"type,omitempty"`   // a named type of Kind the field is converted to
+	Layout    string   `json:"layout,omitempty"` // of a time, a time package constant name or the layout itself
+	ParamName string   `json:"param_name"`
+	Source    string   `json:"source"` // "path" for a {param_name} url segment, "body" on json and auto endpoints but for uploads, "form" otherwise
+	Required  bool     `json:"required,omitempty"`
+	Default   string   `json:"default,omitempty"`
+	Min       string   `json:"min,omitempty"`
+	Max       string   `json:"max,omitempty"`
+	Enum      []string `json:"enum,omitempty"`
+	MaxSize   string   `json:"max_size,omitempty"`   // of an upload, in bytes
+	MimeTypes []string `json:"mime_types,omitempty"` // an upload may have
+}
+
+// Parse is the call parsing raw, the request value of a field of any Kind but
+// "string", into value and err.
+func (field *Field) Parse() string {
+	switch kind := field.Kind; {
+	case kind == "int":
+		return "strconv.Atoi(raw)"
+	case kind == "bool":
+		return "strconv.ParseBool(raw)"
+	case kind == "duration":
+		return "time.ParseDuration(raw)"
+	case kind == "time":
+		layout := "time." + field.layoutName()
+		if _, ok := timeLayouts[field.layoutName()]; !ok {
+			layout = strconv.Quote(field.Layout)
+		}
+		return fmt.Sprintf("time.Parse(%s, raw)", layout)
+	case strings.HasPrefix(kind, "uint"):
+		return fmt.Sprintf("strconv.ParseUint(raw, 10, %d)", kindBits(kind))
+	case strings.HasPrefix(kind, "float"):
+		return fmt.Sprintf("strconv.ParseFloat(raw, %d)", kindBits(kind))
+	}
+	return fmt.Sprintf("strconv.ParseInt(raw, 10, %d)", kindBits(field.Kind))
+}
+
+// Value is value, as parsed by Parse, converted to the type of the field.
+func (field *Field) Value() string {
+	if field.Type != "" {
+		return field.Type + "(value)"
+	}
+	switch field.Kind {
+	case "int", "int64", "uint64", "float64", "bool", "duration", "time":
+		return "value"
+	}
+	return field.Kind + "(value)"
+}
+
+// Expected is what the request value of the field must be, as told when it
+// can not be parsed.
+func (field *Field) Expected() string {
+	if field.Kind == "time" {
+		layout, ok := timeLayouts[field.layoutName()]
+		if !ok {
+			layout = field.Layout
+		}
+		return "time like " + layout
+	}
+	return field.Kind
+}
+
+// Literal writes v, a default, min, max or enum value of the tag, as a Go
+// expression of the type of the field.
+func (field *Field) Literal(v string) string {
+	switch field.Kind {
+	case "string":
+		return strconv.Quote(v)
+	case "duration":
+		d, err := goDuration(v)
+		if err != nil {
+			return v
+		}
+		if field.Type != "" {
+			return field.Type + "(" + d + ")"
+		}
+		return d
+	}
+	return v
+}
+
+// layoutName is the Layout of a time field, RFC3339 by default.
+func (field *Field) layoutName() string {
+	if field.Layout == "" {
+		return "RFC3339"
+	}
+	return field.Layout
+}
+
+// timeLayouts are the layout constants of the time package, by name.
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// kindBits is the bit size of a number Kind, like 16 for "int16", or 0 for
+// "int" and "uint", which strconv takes as the size of an int.
+func kindBits(kind string) int {
+	bits, _ := strconv.Atoi(strings.TrimLeft(kind, "uintfloa"))
+	return bits
+}
@@ -0,0 +1,342 @@
+package apigen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+)
+
+// structField is a field of a param struct the generated handler fills from the
+// request. Promoted fields of embedded structs are listed as if they were
+// declared in the param struct itself.
+type structField struct {
+	Name string // Go name, usable as params.Name
+	// Kind is "string", "bool", the name of a number type ("int", "uint8",
+	// "float64", ...), "time" (time.Time), "duration" (time.Duration), "file"
+	// (serve.UploadedFile) or "bytes" ([]byte).
+	Kind string
+	// Named is the type of the field when it is a named type of Kind, like
+	// type Level int, which values are converted to. It is nil for the type
+	// of Kind itself.
+	Named types.Type
+	Tag   string // value of the apivalidator tag
+	Pos   token.Pos
+}
+
+// signature is what the generated handler needs to know about the
+// signature of an API method: (ctx context.Context, in ParamStruct) (T,
+// error) where the context, the param struct and T are all optional.
+type signature struct {
+	Context bool       // the method takes a context.Context, first
+	Param   types.Type // the param struct, nil if there is none
+	Fields  []structField
+	Result  types.Type // T, nil when the method only returns an error
+}
+
+// signature checks the signature of an API method and lists the fields of
+// its param struct. The struct may be declared in any file of the package or
+// in an imported package, and may be reached through type aliases. Problems
+// are reported to rep; ok is false when the method can not be called from a
+// handler at all.
+func (pkg *Package) signature(rep *reporter, funcDecl *ast.FuncDecl) (sig signature, ok bool) {
+	name := funcDecl.Name.Name
+	fn, isFunc := pkg.Info.Defs[funcDecl.Name].(*types.Func)
+	if !isFunc {
+		rep.errorf(funcDecl.Name.Pos(), "%s is not type-checked", name)
+		return sig, false
+	}
+	ok = true
+	fnType := fn.Type().(*types.Signature)
+	qualifier := types.RelativeTo(pkg.Types)
+
+	params := fnType.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		switch {
+		case isContext(param.Type()):
+			if i != 0 {
+				rep.errorf(param.Pos(), "context.Context must be the first param of %s", name)
+				ok = false
+			}
+			sig.Context = true
+		case sig.Param != nil:
+			rep.errorf(param.Pos(), "%s has more than one param struct, it takes at most (ctx context.Context, in ParamStruct)", name)
+			ok = false
+		default:
+			st, isStruct := param.Type().Underlying().(*types.Struct)
+			if !isStruct {
+				rep.errorf(param.Pos(), "param %s of %s is %s, not a struct", param.Name(), name, types.TypeString(param.Type(), qualifier))
+				ok = false
+				continue
+			}
+			sig.Param = param.Type()
+			sig.Fields = pkg.structFields(rep, st)
+		}
+	}
+
+	results := fnType.Results()
+	last := results.Len() - 1
+	if last < 0 || last > 1 || !isError(results.At(last).Type()) {
+		rep.errorf(funcDecl.Name.Pos(), "%s must return error or (T, error), not %s", name, types.TypeString(results, qualifier))
+		return sig, false
+	}
+	if last == 1 {
+		sig.Result = results.At(0).Type()
+	}
+	return sig, ok
+}
+
+// statusCoder is the interface of the results that choose their status,
+// serve.StatusCoder.
+var statusCoder = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "StatusCode", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.Int])), false)),
+}, nil).Complete()
+
+// isStatusCoder reports whether the results of type t have a StatusCode()
+// int method.
+func isStatusCoder(t types.Type) bool {
+	return types.Implements(t, statusCoder)
+}
+
+// streamFormat checks the stream format of an API method with the result
+// type result: methods returning a channel stream its items, by default in
+// the format the request accepts ("auto"). A stream format for any other
+// result is reported at pos, as well as an unknown format.
+func streamFormat(rep *reporter, pos token.Pos, name, stream string, result types.Type) string {
+	var ch *types.Chan
+	if result != nil {
+		ch, _ = result.Underlying().(*types.Chan)
+	}
+	switch {
+	case ch == nil:
+		if stream != "" {
+			rep.errorf(pos, "stream only applies to methods returning a channel, not %s", name)
+		}
+		return ""
+	case ch.Dir() == types.SendOnly:
+		rep.errorf(pos, "%s returns a send-only channel, it can not be streamed", name)
+		return ""
+	}
+	switch stream {
+	case "":
+		return "auto"
+	case "ndjson", "sse", "auto":
+		return stream
+	}
+	rep.errorf(pos, "stream must be \"ndjson\", \"sse\" or \"auto\", not %q", stream)
+	return ""
+}
+
+// structFields flattens st: fields of embedded structs are promoted in place,
+// fields that can not be set from the generated package are left out.
+func (pkg *Package) structFields(rep *reporter, st *types.Struct) []structField {
+	var fields []structField
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get("apivalidator")
+		if field.Embedded() {
+			if _, isPointer := types.Unalias(field.Type()).(*types.Pointer); isPointer {
+				rep.errorf(field.Pos(), "embedded pointer %s is not supported", field.Name())
+				continue
+			}
+			if embedded, ok := field.Type().Underlying().(*types.Struct); ok {
+				fields = append(fields, pkg.structFields(rep, embedded)...)
+			}
+			continue
+		}
+		if !field.Exported() && field.Pkg() != pkg.Types {
+			continue
+		}
+		kind, named := pkg.fieldKind(field.Type())
+		if kind == "" {
+			rep.errorf(field.Pos(), "field %s has unsupported type %s", field.Name(), field.Type())
+			continue
+		}
+		sf := structField{
+			Name: field.Name(),
+			Kind: kind,
+			Tag:  tag,
+			Pos:  field.Pos(),
+		}
+		if named {
+			sf.Named = field.Type()
+		}
+		fields = append(fields, sf)
+	}
+	return fields
+}
+
+// basicKinds are the structField.Kind of the basic types a field may have.
+var basicKinds = map[types.BasicKind]string{
+	types.String:  "string",
+	types.Bool:    "bool",
+	types.Int:     "int",
+	types.Int8:    "int8",
+	types.Int16:   "int16",
+	types.Int32:   "int32",
+	types.Int64:   "int64",
+	types.Uint:    "uint",
+	types.Uint8:   "uint8",
+	types.Uint16:  "uint16",
+	types.Uint32:  "uint32",
+	types.Uint64:  "uint64",
+	types.Float32: "float32",
+	types.Float64: "float64",
+}
+
+// fieldKind is the structField.Kind of a field of type t, "" for the types
+// that can not be bound from a request. named is set when t is a named type
+// of the kind rather than its own type, like type Level int.
+func (pkg *Package) fieldKind(t types.Type) (kind string, named bool) {
+	t = types.Unalias(t)
+	if n, ok := t.(*types.Named); ok {
+		obj := n.Obj()
+		switch {
+		case isObject(obj, servePath, "UploadedFile"):
+			return "file", false
+		case isObject(obj, "time", "Time"):
+			return "time", false
+		case isObject(obj, "time", "Duration"):
+			return "duration", false
+		}
+		// a type declared as another named type, like time.Duration, is of
+		// its kind, which the underlying type does not tell
+		if declared, ok := types.Unalias(pkg.declaredType(obj)).(*types.Named); ok {
+			kind, _ := pkg.fieldKind(declared)
+			if kind == "file" {
+				// bound by dereferencing the upload, not converting it
+				return "", false
+			}
+			return kind, kind != ""
+		}
+		t = n.Underlying()
+		named = true
+	}
+	switch t := t.(type) {
+	case *types.Basic:
+		return basicKinds[t.Kind()], named
+	case *types.Slice:
+		if elem, ok := types.Unalias(t.Elem()).(*types.Basic); ok && elem.Kind() == types.Byte {
+			return "bytes", false
+		}
+	}
+	return "", false
+}
+
+// declaredType is the type obj is declared as, like time.Duration for type
+// Timeout time.Duration, or nil when that can not be told. A type of another
+// package is looked up in the source of that package.
+func (pkg *Package) declaredType(obj *types.TypeName) types.Type {
+	if obj.Pkg() == nil {
+		return nil
+	}
+	declaring := pkg
+	if obj.Pkg() != pkg.Types {
+		if declaring = pkg.importedPackage(obj.Pkg().Path()); declaring == nil {
+			return nil
+		}
+	}
+	for _, file := range declaring.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name == obj.Name() {
+					return declaring.Info.TypeOf(typeSpec.Type)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importedPackage loads the package of the import path, as found from the
+// directory of pkg, once. It is nil when that fails.
+func (pkg *Package) importedPackage(path string) *Package {
+	if imported, ok := pkg.imported[path]; ok {
+		return imported
+	}
+	var imported *Package
+	if bp, err := build.Import(path, pkg.Dir, build.FindOnly); err == nil {
+		imported, _ = LoadPackage(bp.Dir)
+	}
+	if pkg.imported == nil {
+		pkg.imported = make(map[string]*Package)
+	}
+	pkg.imported[path] = imported
+	return imported
+}
+
+// isObject reports whether obj is the object name of the package path.
+func isObject(obj types.Object, path, name string) bool {
+	return obj.Pkg() != nil && obj.Pkg().Path() == path && obj.Name() == name
+}
+
+// isError reports whether t is the error interface.
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// isContext reports whether t is context.Context.
+func isContext(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && isObject(named.Obj(), "context", "Context")
+}
+
+// importSet collects the packages the generated code refers to, by import
+// path, with the names they are referred to by.
+type importSet map[string]string
+
+// add records the package of path and returns its name in the generated
+// file: name, unless another package has it already, like encoding/json
+// for a package named json, which then gets name2, name3, ...
+func (imports importSet) add(path, name string) string {
+	if alias, ok := imports[path]; ok {
+		return alias
+	}
+	alias := name
+	for i := 2; imports.taken(alias); i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	imports[path] = alias
+	return alias
+}
+
+// taken reports whether a package is referred to by name.
+func (imports importSet) taken(name string) bool {
+	for _, alias := range imports {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName is the name of t as written in the generated package. Packages t
+// refers to are recorded in imports.
+func (pkg *Package) typeName(t types.Type, imports importSet) string {
+	return types.TypeString(t, func(other *types.Package) string {
+		if other == pkg.Types {
+			return ""
+		}
+		return imports.add(other.Path(), other.Name())
+	})
+}
+
+// sorted lists the import paths in order.
+func (imports importSet) sorted() []string {
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
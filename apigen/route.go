@@ -0,0 +1,76 @@
+package apigen
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// pathParamRe is a {name} segment of an endpoint url.
+var pathParamRe = regexp.MustCompile(`^\{(\w+)\}$`)
+
+// pathParams lists the names of the {name} segments of url, in order.
+// Malformed and repeated segments are reported at pos.
+func pathParams(rep *reporter, pos token.Pos, url string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(url, "/") {
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+		match := pathParamRe.FindStringSubmatch(segment)
+		if match == nil {
+			rep.errorf(pos, "bad url segment %q, path params take a whole segment: {name}", segment)
+			continue
+		}
+		if seen[match[1]] {
+			rep.errorf(pos, "path param {%s} is repeated in %s", match[1], url)
+			continue
+		}
+		seen[match[1]] = true
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// urlShape is url with every {name} segment written {}: urls of the same
+// shape match the same requests.
+func urlShape(url string) string {
+	segments := strings.Split(url, "/")
+	for i, segment := range segments {
+		if pathParamRe.MatchString(segment) {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// checkRoutes reports the endpoints of api that could never be reached: those
+// sharing a url (by shape) and a method with an earlier one. Endpoints of the
+// same route must also name their path params alike, the route binds them
+// by the names of its first endpoint.
+func checkRoutes(rep *reporter, api *API) {
+	for _, route := range api.Routes() {
+		owners := make(map[string]*Endpoint)
+		var fallback *Endpoint
+		for _, endpoint := range route.Endpoints {
+			if endpoint.URL != route.URL {
+				rep.errorf(endpoint.pos, "%s and %s serve the same path, %s must name its path params like %s", route.Endpoints[0].FullName(), endpoint.FullName(), endpoint.URL, route.URL)
+			}
+			if len(endpoint.HTTPMethods) == 0 {
+				if fallback != nil {
+					rep.errorf(endpoint.pos, "%s and %s both serve any method of %s", fallback.FullName(), endpoint.FullName(), route.URL)
+				} else {
+					fallback = endpoint
+				}
+			}
+			for _, method := range endpoint.HTTPMethods {
+				if owner, ok := owners[method]; ok {
+					rep.errorf(endpoint.pos, "%s and %s both serve %s %s", owner.FullName(), endpoint.FullName(), method, route.URL)
+				} else {
+					owners[method] = endpoint
+				}
+			}
+		}
+	}
+}
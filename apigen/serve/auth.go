@@ -0,0 +1,115 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrUnauthenticated is returned by authenticators for requests without
+// valid credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Principal is who a request is authenticated as.
+type Principal struct {
+	ID     string
+	Roles  []string
+	Claims Claims // of the JWT the principal comes from, if any
+}
+
+// HasRole tells whether the principal has any of roles.
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		for _, has := range p.Roles {
+			if has == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticator tells who sent a request. It returns an error, usually
+// ErrUnauthenticated, when the request can not be authenticated.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(r *http.Request) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Principal, error) {
+	return f(r)
+}
+
+// AuthenticatorProvider is implemented by the API structs that bring their
+// own Authenticator.
+type AuthenticatorProvider interface {
+	Authenticator() Authenticator
+}
+
+// TokenStore finds the principal a token belongs to.
+type TokenStore interface {
+	Lookup(token string) (*Principal, bool)
+}
+
+// StaticTokens is a fixed TokenStore, the principal of a token has the token
+// as its ID.
+type StaticTokens []string
+
+func (tokens StaticTokens) Lookup(token string) (*Principal, bool) {
+	for _, known := range tokens {
+		if token != "" && token == known {
+			return &Principal{ID: token}, true
+		}
+	}
+	return nil, false
+}
+
+// HeaderToken authenticates the requests whose Header holds a token of Store.
+type HeaderToken struct {
+	Header string
+	Store  TokenStore
+}
+
+func (h HeaderToken) Authenticate(r *http.Request) (*Principal, error) {
+	principal, ok := h.Store.Lookup(r.Header.Get(h.Header))
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+// DefaultAuthenticator is used for the API structs that are not an
+// AuthenticatorProvider: the X-Auth header must hold the token 100500.
+var DefaultAuthenticator Authenticator = HeaderToken{
+	Header: "X-Auth",
+	Store:  StaticTokens{"100500"},
+}
+
+// Authenticate authenticates r with the Authenticator of api. A nil principal
+// is an error too.
+func Authenticate(api interface{}, r *http.Request) (*Principal, error) {
+	authenticator := DefaultAuthenticator
+	if provider, ok := api.(AuthenticatorProvider); ok {
+		authenticator = provider.Authenticator()
+	}
+	principal, err := authenticator.Authenticate(r)
+	if err == nil && principal == nil {
+		err = ErrUnauthenticated
+	}
+	return principal, err
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal the request of ctx is authenticated as.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,59 @@
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// MaxBodyBytes is the size of the largest JSON body JSONBody decodes.
+var MaxBodyBytes int64 = 1 << 20
+
+// IsJSON reports whether the Content-Type of r is JSON.
+func IsJSON(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+// JSONBody decodes the body of r, a JSON object, and returns the values of
+// its keys in the form r.FormValue returns them: strings as they are,
+// numbers and bools as written, an absent key or null as "". Objects and
+// arrays are an error, as well as a malformed body.
+func JSONBody(r *http.Request) (func(key string) string, error) {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, MaxBodyBytes))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("empty body")
+		}
+		return nil, fmt.Errorf("bad json body: %v", err)
+	}
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		switch value := value.(type) {
+		case nil:
+		case string:
+			values[key] = value
+		case json.Number:
+			values[key] = value.String()
+		case bool:
+			values[key] = fmt.Sprint(value)
+		default:
+			return nil, fmt.Errorf("bad json body: %s must be a string, a number or a bool", key)
+		}
+	}
+	return func(key string) string { return values[key] }, nil
+}
+
+// BodyValues returns the values of the body of r: the ones of JSONBody
+// when it is JSON, r.FormValue otherwise.
+func BodyValues(r *http.Request) (func(key string) string, error) {
+	if IsJSON(r) {
+		return JSONBody(r)
+	}
+	return r.FormValue, nil
+}
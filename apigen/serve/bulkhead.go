@@ -0,0 +1,89 @@
+package serve
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bulkheads caps the number of concurrent calls of endpoints, a bulkhead per
+// endpoint. A call over the cap waits for up to Wait for a free slot.
+type Bulkheads struct {
+	Wait time.Duration
+
+	mu    sync.Mutex
+	heads map[string]*bulkhead
+}
+
+type bulkhead struct {
+	slots chan struct{}
+}
+
+// BulkheadsProvider is implemented by the API structs that keep their
+// bulkheads apart from DefaultBulkheads.
+type BulkheadsProvider interface {
+	Bulkheads() *Bulkheads
+}
+
+// DefaultBulkheads are the bulkheads of the API structs that are not a
+// BulkheadsProvider.
+var DefaultBulkheads = NewBulkheads(100 * time.Millisecond)
+
+func NewBulkheads(wait time.Duration) *Bulkheads {
+	return &Bulkheads{Wait: wait, heads: make(map[string]*bulkhead)}
+}
+
+// Enter takes a slot of the bulkhead of endpoint, which has max of them. It
+// returns false when none got free within Wait or before ctx is done;
+// otherwise the slot is the caller's until it calls release.
+func (b *Bulkheads) Enter(ctx context.Context, endpoint string, max int) (release func(), ok bool) {
+	b.mu.Lock()
+	head, ok := b.heads[endpoint]
+	if !ok {
+		head = &bulkhead{slots: make(chan struct{}, max)}
+		b.heads[endpoint] = head
+	}
+	b.mu.Unlock()
+
+	release = func() { <-head.slots }
+	select {
+	case head.slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	if b.Wait <= 0 {
+		return nil, false
+	}
+	timer := time.NewTimer(b.Wait)
+	defer timer.Stop()
+	select {
+	case head.slots <- struct{}{}:
+		return release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// InFlight returns the number of calls running in the bulkhead of each
+// endpoint that has been entered.
+func (b *Bulkheads) InFlight() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inFlight := make(map[string]int, len(b.heads))
+	for endpoint, head := range b.heads {
+		inFlight[endpoint] = len(head.slots)
+	}
+	return inFlight
+}
+
+// Enter takes a slot of the bulkhead of endpoint in the Bulkheads of api,
+// see Bulkheads.Enter.
+func Enter(ctx context.Context, api interface{}, endpoint string, max int) (release func(), ok bool) {
+	bulkheads := DefaultBulkheads
+	if provider, ok := api.(BulkheadsProvider); ok {
+		bulkheads = provider.Bulkheads()
+	}
+	return bulkheads.Enter(ctx, endpoint, max)
+}
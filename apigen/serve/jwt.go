@@ -0,0 +1,186 @@
+package serve
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The reasons a JWT is rejected for.
+var (
+	ErrTokenMalformed   = errors.New("malformed token")
+	ErrTokenSignature   = errors.New("bad token signature")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not valid yet")
+	ErrTokenAudience    = errors.New("token not meant for this audience")
+)
+
+// Claims are the claims of a verified JWT.
+type Claims map[string]interface{}
+
+// JWT authenticates the requests with an "Authorization: Bearer" JSON Web
+// Token signed with HS256 by Secret. The exp and nbf claims are checked when
+// present, aud must contain Audience when it is set. The principal has the sub
+// claim as its ID, the roles claim as its roles and all the claims as its
+// Claims.
+type JWT struct {
+	Realm    string
+	Secret   []byte
+	Audience string
+	// Leeway is the clock skew tolerated by the exp and nbf checks.
+	Leeway time.Duration
+	// Now is the current time, time.Now when nil.
+	Now func() time.Time
+}
+
+var jwtEncoding = base64.RawURLEncoding
+
+// jwtHeader is the header Sign writes and Verify accepts.
+const jwtHeader = `{"alg":"HS256","typ":"JWT"}`
+
+func (j JWT) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrUnauthenticated
+	}
+	claims, err := j.Verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+	principal := &Principal{Claims: claims}
+	principal.ID, _ = claims["sub"].(string)
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				principal.Roles = append(principal.Roles, role)
+			}
+		}
+	}
+	return principal, nil
+}
+
+func (j JWT) Challenge() string {
+	return `Bearer realm="` + j.Realm + `"`
+}
+
+// Verify checks the signature and the claims of token and returns them.
+func (j JWT) Verify(token string) (Claims, error) {
+	if len(j.Secret) == 0 {
+		return nil, ErrTokenSignature
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "HS256" {
+		return nil, ErrTokenSignature
+	}
+	signature, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if !hmac.Equal(signature, j.sign(parts[0]+"."+parts[1])) {
+		return nil, ErrTokenSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if j.Now != nil {
+		now = j.Now
+	}
+	if exp, ok := claims["exp"]; ok {
+		exp, ok := exp.(float64)
+		if !ok {
+			return nil, ErrTokenMalformed
+		}
+		if !now().Before(unixTime(exp).Add(j.Leeway)) {
+			return nil, ErrTokenExpired
+		}
+	}
+	if nbf, ok := claims["nbf"]; ok {
+		nbf, ok := nbf.(float64)
+		if !ok {
+			return nil, ErrTokenMalformed
+		}
+		if now().Add(j.Leeway).Before(unixTime(nbf)) {
+			return nil, ErrTokenNotYetValid
+		}
+	}
+	if j.Audience != "" && !claims.hasAudience(j.Audience) {
+		return nil, ErrTokenAudience
+	}
+	return claims, nil
+}
+
+// Sign returns an HS256 JWT of claims signed by Secret, the counterpart of
+// Verify for issuing tokens.
+func (j JWT) Sign(claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtEncoding.EncodeToString([]byte(jwtHeader)) + "." + jwtEncoding.EncodeToString(payload)
+	return unsigned + "." + jwtEncoding.EncodeToString(j.sign(unsigned)), nil
+}
+
+func (j JWT) sign(unsigned string) []byte {
+	mac := hmac.New(sha256.New, j.Secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+// hasAudience tells whether the aud claim, a string or a list of them,
+// contains audience.
+func (claims Claims) hasAudience(audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, one := range aud {
+			if one == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := jwtEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrTokenMalformed
+	}
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// ClaimsFrom returns the claims of the JWT the request of ctx is
+// authenticated with.
+func ClaimsFrom(ctx context.Context) (Claims, bool) {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok || principal.Claims == nil {
+		return nil, false
+	}
+	return principal.Claims, true
+}
@@ -0,0 +1,75 @@
+package serve
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	verifier := JWT{
+		Secret:   []byte("secret"),
+		Audience: "api",
+		Leeway:   time.Minute,
+		Now:      func() time.Time { return now },
+	}
+	sign := func(signer JWT, claims Claims) string {
+		token, err := signer.Sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := sign(verifier, Claims{"sub": "rvasily", "aud": "api", "exp": now.Unix() + 60})
+
+	cases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", valid, nil},
+		{"audience list", sign(verifier, Claims{"aud": []string{"web", "api"}}), nil},
+		{"within leeway", sign(verifier, Claims{"aud": "api", "exp": now.Unix() - 30}), nil},
+		{"expired", sign(verifier, Claims{"aud": "api", "exp": now.Unix() - 61}), ErrTokenExpired},
+		{"not yet valid", sign(verifier, Claims{"aud": "api", "nbf": now.Unix() + 61}), ErrTokenNotYetValid},
+		{"other audience", sign(verifier, Claims{"aud": "web"}), ErrTokenAudience},
+		{"no audience", sign(verifier, Claims{}), ErrTokenAudience},
+		{"other secret", sign(JWT{Secret: []byte("guess")}, Claims{"aud": "api"}), ErrTokenSignature},
+		{"tampered", valid[:strings.LastIndex(valid, ".")-1] + "x" + valid[strings.LastIndex(valid, "."):], ErrTokenSignature},
+		{"malformed", "not.a-token", ErrTokenMalformed},
+		{"alg none", jwtEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + jwtEncoding.EncodeToString([]byte(`{"aud":"api"}`)) + ".", ErrTokenSignature},
+	}
+	for _, c := range cases {
+		if _, err := verifier.Verify(c.token); err != c.err {
+			t.Errorf("%s: expected %v, got %v", c.name, c.err, err)
+		}
+	}
+
+	if _, err := (JWT{}).Verify(sign(JWT{}, Claims{})); err != ErrTokenSignature {
+		t.Errorf("a JWT without a secret must reject everything, got %v", err)
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	verifier := JWT{Secret: []byte("secret")}
+	token, err := verifier.Sign(Claims{"sub": "rvasily", "roles": []string{"admin"}, "level": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	principal, err := verifier.Authenticate(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.ID != "rvasily" || !principal.HasRole("admin") || principal.Claims["level"] != float64(10) {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+
+	ctx := WithPrincipal(r.Context(), principal)
+	if claims, ok := ClaimsFrom(ctx); !ok || claims["sub"] != "rvasily" {
+		t.Errorf("expected the claims in the context, got %v", claims)
+	}
+}
@@ -0,0 +1,132 @@
+package serve
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limit is a rate limit: Requests per Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Limiter decides whether a request under a rate limit may go on.
+type Limiter interface {
+	// Allow takes one request off the allowance of key. When none is left it
+	// returns false and how long to wait for the next one.
+	Allow(key string, limit Limit) (ok bool, retryAfter time.Duration)
+}
+
+// LimiterProvider is implemented by the API structs that keep their rate
+// limits in a Limiter of their own.
+type LimiterProvider interface {
+	Limiter() Limiter
+}
+
+// DefaultLimiter holds the rate limits of the API structs that are not a
+// LimiterProvider.
+var DefaultLimiter Limiter = NewTokenBuckets()
+
+// TokenBuckets is an in-process Limiter with a token bucket per key. A
+// bucket holds up to limit.Requests tokens and is refilled at that rate.
+type TokenBuckets struct {
+	// Now is the current time, time.Now when nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweepAt int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	per    time.Duration // of the limit the bucket is for
+}
+
+// bucketsSweep is how many buckets TokenBuckets keeps before it first drops
+// the full ones.
+const bucketsSweep = 1024
+
+func NewTokenBuckets() *TokenBuckets {
+	return &TokenBuckets{buckets: make(map[string]*bucket), sweepAt: bucketsSweep}
+}
+
+func (tb *TokenBuckets) Allow(key string, limit Limit) (bool, time.Duration) {
+	now := time.Now()
+	if tb.Now != nil {
+		now = tb.Now()
+	}
+	perToken := limit.Per / time.Duration(limit.Requests)
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	b, ok := tb.buckets[key]
+	if !ok {
+		if len(tb.buckets) >= tb.sweepAt {
+			tb.sweep(now)
+		}
+		b = &bucket{tokens: float64(limit.Requests), last: now, per: limit.Per}
+		tb.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that are full by now, they are the same as new
+// ones. Buckets are only swept once their number doubled since the last
+// sweep, which keeps Allow cheap on average.
+func (tb *TokenBuckets) sweep(now time.Time) {
+	for key, b := range tb.buckets {
+		if now.Sub(b.last) >= b.per {
+			delete(tb.buckets, key)
+		}
+	}
+	tb.sweepAt = 2 * len(tb.buckets)
+	if tb.sweepAt < bucketsSweep {
+		tb.sweepAt = bucketsSweep
+	}
+}
+
+// Client is the key rate limits count the requests of ctx and r under: the
+// ID of the principal for authenticated requests, the remote IP otherwise.
+func Client(ctx context.Context, r *http.Request) string {
+	if principal, ok := PrincipalFrom(ctx); ok {
+		return "principal:" + principal.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// AllowRequest applies limit to the request of ctx and r for the endpoint
+// named endpoint, in the Limiter of api. A rejected request gets its
+// Retry-After header set on w, in whole seconds.
+func AllowRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, api interface{}, endpoint string, limit Limit) bool {
+	limiter := DefaultLimiter
+	if provider, ok := api.(LimiterProvider); ok {
+		limiter = provider.Limiter()
+	}
+	ok, retryAfter := limiter.Allow(endpoint+" "+Client(ctx, r), limit)
+	if !ok {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return ok
+}
@@ -0,0 +1,168 @@
+package serve
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// The authentication schemes an "auth_schemes" annotation may list.
+const (
+	SchemeXAuth  = "x-auth" // a token in the X-Auth header
+	SchemeBearer = "bearer" // Authorization: Bearer <token>
+	SchemeBasic  = "basic"  // HTTP Basic authentication
+	SchemeQuery  = "query"  // an api_key query param
+	SchemeJWT    = "jwt"    // Authorization: Bearer <HS256 JWT>
+)
+
+// Scheme is an Authenticator for one way of sending credentials.
+type Scheme interface {
+	Authenticator
+	// Challenge is the WWW-Authenticate value asking for credentials of the
+	// scheme, empty for the schemes HTTP has no challenge for.
+	Challenge() string
+}
+
+// Schemes maps scheme names to the verifiers of their credentials.
+type Schemes map[string]Scheme
+
+// SchemesProvider is implemented by the API structs that verify the
+// credentials of some schemes themselves. The schemes missing from their
+// Schemes are taken from DefaultSchemes.
+type SchemesProvider interface {
+	AuthSchemes() Schemes
+}
+
+// DefaultSchemes accept the token 100500 in the token schemes. Basic has no
+// users and JWT no secret by default, they reject everything until an API
+// configures them.
+var DefaultSchemes = Schemes{
+	SchemeXAuth:  HeaderToken{Header: "X-Auth", Store: StaticTokens{"100500"}},
+	SchemeBearer: Bearer{Realm: "api", Store: StaticTokens{"100500"}},
+	SchemeBasic:  Basic{Realm: "api", Store: StaticPasswords{}},
+	SchemeQuery:  QueryToken{Param: "api_key", Store: StaticTokens{"100500"}},
+	SchemeJWT:    JWT{Realm: "api"},
+}
+
+func (h HeaderToken) Challenge() string {
+	return ""
+}
+
+// Bearer authenticates the requests with an "Authorization: Bearer" token of
+// Store.
+type Bearer struct {
+	Realm string
+	Store TokenStore
+}
+
+func (b Bearer) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrUnauthenticated
+	}
+	principal, ok := b.Store.Lookup(strings.TrimSpace(token))
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+func (b Bearer) Challenge() string {
+	return `Bearer realm="` + b.Realm + `"`
+}
+
+// PasswordStore checks user passwords.
+type PasswordStore interface {
+	Check(user, password string) (*Principal, bool)
+}
+
+// StaticPasswords is a fixed PasswordStore of user passwords, the principal
+// of a user has the user name as its ID.
+type StaticPasswords map[string]string
+
+func (passwords StaticPasswords) Check(user, password string) (*Principal, bool) {
+	known, ok := passwords[user]
+	if !ok || subtle.ConstantTimeCompare([]byte(known), []byte(password)) != 1 {
+		return nil, false
+	}
+	return &Principal{ID: user}, true
+}
+
+// Basic authenticates the requests with HTTP Basic credentials of Store.
+type Basic struct {
+	Realm string
+	Store PasswordStore
+}
+
+func (b Basic) Authenticate(r *http.Request) (*Principal, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	principal, ok := b.Store.Check(user, password)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+func (b Basic) Challenge() string {
+	return `Basic realm="` + b.Realm + `", charset="UTF-8"`
+}
+
+// QueryToken authenticates the requests whose Param query param holds a
+// token of Store.
+type QueryToken struct {
+	Param string
+	Store TokenStore
+}
+
+func (q QueryToken) Authenticate(r *http.Request) (*Principal, error) {
+	principal, ok := q.Store.Lookup(r.URL.Query().Get(q.Param))
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+func (q QueryToken) Challenge() string {
+	return ""
+}
+
+// scheme returns the verifier api uses for the scheme name, nil for an
+// unknown one.
+func scheme(api interface{}, name string) Scheme {
+	if provider, ok := api.(SchemesProvider); ok {
+		if scheme, ok := provider.AuthSchemes()[name]; ok {
+			return scheme
+		}
+	}
+	return DefaultSchemes[name]
+}
+
+// AuthenticateSchemes tries the schemes of api named by schemes in order and
+// returns the principal of the first one r has valid credentials for.
+func AuthenticateSchemes(api interface{}, r *http.Request, schemes ...string) (*Principal, error) {
+	for _, name := range schemes {
+		scheme := scheme(api, name)
+		if scheme == nil {
+			continue
+		}
+		if principal, err := scheme.Authenticate(r); err == nil && principal != nil {
+			return principal, nil
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// Challenge adds the WWW-Authenticate challenges of the schemes of api named
+// by schemes to w, for a request none of them could authenticate.
+func Challenge(w http.ResponseWriter, api interface{}, schemes ...string) {
+	for _, name := range schemes {
+		if scheme := scheme(api, name); scheme != nil {
+			if challenge := scheme.Challenge(); challenge != "" {
+				w.Header().Add("WWW-Authenticate", challenge)
+			}
+		}
+	}
+}
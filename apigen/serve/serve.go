@@ -0,0 +1,18 @@
+// Package serve is what the handlers generated by apigen rely on at run time.
+//
+// Endpoints annotated with "auth": true authenticate their requests through an
+// Authenticator. An API struct chooses its own by implementing
+// AuthenticatorProvider, the others share DefaultAuthenticator. The Principal
+// a request is authenticated as reaches the API method through its context,
+// see PrincipalFrom. Endpoints annotated with "auth_schemes" try the named
+// Schemes in order instead and challenge the client with WWW-Authenticate
+// when none of them succeeds. JWT verifies HS256 tokens, see ClaimsFrom.
+//
+// CallTimeout bounds the calls of the endpoints with a "timeout" and
+// AllowRequest enforces "rate_limit" with a Limiter. Enter caps the
+// concurrent calls of the endpoints with "max_in_flight", see Bulkheads.
+// JSONBody and BodyValues read the params of "body" endpoints, FormFile
+// reads uploads. Stream writes the results of the methods returning a
+// channel. Endpoints annotated with "roles" also require the
+// principal to have one of them, see Principal.HasRole.
+package serve
@@ -0,0 +1,19 @@
+package serve
+
+// StatusCoder is implemented by the results that choose the status of a
+// successful response themselves, such as 201 for a created resource or 204
+// for no content.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Status is the status of the successful response with res: the StatusCode
+// of res when it is a StatusCoder that returns non-zero, status otherwise.
+func Status(res interface{}, status int) int {
+	if coder, ok := res.(StatusCoder); ok {
+		if code := coder.StatusCode(); code != 0 {
+			return code
+		}
+	}
+	return status
+}
@@ -0,0 +1,69 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// The formats Stream writes items in.
+const (
+	StreamNDJSON = "ndjson" // a JSON document per line
+	StreamSSE    = "sse"    // server-sent events, a data event per item
+	StreamAuto   = "auto"   // SSE when the request accepts text/event-stream, NDJSON otherwise
+)
+
+// StreamFormat resolves StreamAuto for r, other formats are returned as
+// they are.
+func StreamFormat(r *http.Request, format string) string {
+	if format != StreamAuto {
+		return format
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, "text/event-stream") {
+			return StreamSSE
+		}
+	}
+	return StreamNDJSON
+}
+
+// Stream writes the items of a streaming method to w as they come, flushing
+// after each of them, until items is closed or ctx is done. The method is
+// expected to stop sending once ctx is done, nothing reads items after that.
+func Stream[T any](ctx context.Context, w http.ResponseWriter, r *http.Request, format string, status int, items <-chan T) {
+	format = StreamFormat(r, format)
+	if format == StreamSSE {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	flusher := http.NewResponseController(w)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item, ok := <-items:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(item)
+			if err != nil {
+				data, _ = json.Marshal(map[string]string{"error": err.Error()})
+			}
+			if format == StreamSSE {
+				data = append(append([]byte("data: "), data...), "\n\n"...)
+			} else {
+				data = append(data, '\n')
+			}
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by CallTimeout for calls that run out of time.
+var ErrTimeout = errors.New("timeout")
+
+// CallTimeout runs call with a context that is done after timeout. If call
+// has not returned by then, CallTimeout does not wait for it and returns
+// ErrTimeout; call keeps running in the background, its result is dropped.
+// A call that gives up on the deadline itself gets ErrTimeout as well.
+//
+// A panic of call is raised again by CallTimeout, on the goroutine of the
+// handler, where net/http recovers it like the panic of any handler; once
+// CallTimeout has returned it is dropped, not to take the process down.
+func CallTimeout(ctx context.Context, timeout time.Duration, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		res   interface{}
+		err   error
+		panic interface{}
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{panic: p}
+			}
+		}()
+		res, err := call(ctx)
+		done <- result{res: res, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.panic != nil {
+			panic(result.panic)
+		}
+		if result.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return result.res, result.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,52 @@
+package serve
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCallTimeout(t *testing.T) {
+	res, err := CallTimeout(context.Background(), time.Second, func(ctx context.Context) (interface{}, error) {
+		return 42, nil
+	})
+	if res != 42 || err != nil {
+		t.Errorf("expected 42, nil, got %v, %v", res, err)
+	}
+
+	_, err = CallTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	})
+	if err != ErrTimeout {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestCallTimeoutPanic(t *testing.T) {
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("expected the panic of the call, got %v", p)
+			}
+		}()
+		CallTimeout(context.Background(), time.Second, func(ctx context.Context) (interface{}, error) {
+			panic("boom")
+		})
+		t.Error("expected CallTimeout to panic")
+	}()
+
+	// a panic after the timeout has nobody to go to, it must not crash
+	panicked := make(chan struct{})
+	_, err := CallTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		defer close(panicked)
+		panic("late")
+	})
+	if err != ErrTimeout {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	<-panicked
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,80 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// UploadedFile is a file of a multipart/form-data request. Params fields of
+// this type, or of the UploadedFile type generated into a package that
+// refers to one without declaring it, are bound from the part named by their
+// paramname.
+type UploadedFile struct {
+	Filename string
+	// ContentType is sniffed from Data, what the client claims is ignored
+	ContentType string
+	Data        []byte
+}
+
+// FormFile reads the file of r named name. A request without one gives a nil
+// file and no error. When maxSize is positive a larger file is an error; so
+// is a file of none of mimeTypes, when given. A mime type may be a whole
+// family: "image/*".
+func FormFile(r *http.Request, name string, maxSize int64, mimeTypes ...string) (*UploadedFile, error) {
+	part, header, err := r.FormFile(name)
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad %s upload: %v", name, err)
+	}
+	defer part.Close()
+
+	tooLarge := fmt.Errorf("%s size must be <= %d", name, maxSize)
+	if maxSize > 0 && header.Size > maxSize {
+		return nil, tooLarge
+	}
+	reader := io.Reader(part)
+	if maxSize > 0 {
+		reader = io.LimitReader(part, maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("bad %s upload: %v", name, err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, tooLarge
+	}
+
+	file := &UploadedFile{
+		Filename:    header.Filename,
+		ContentType: http.DetectContentType(data),
+		Data:        data,
+	}
+	if len(mimeTypes) > 0 && !matchMimeType(file.ContentType, mimeTypes) {
+		return nil, fmt.Errorf("%s must be one of [%s]", name, strings.Join(mimeTypes, ", "))
+	}
+	return file, nil
+}
+
+// matchMimeType reports whether contentType is one of mimeTypes.
+func matchMimeType(contentType string, mimeTypes []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, mimeType := range mimeTypes {
+		if family, ok := strings.CutSuffix(mimeType, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") {
+				return true
+			}
+		} else if mediaType == mimeType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package apigen
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+var templateFuncs = template.FuncMap{
+	"join":       strings.Join,
+	"duration":   goDuration,
+	"status":     goStatus,
+	"routerName": func() string { return routerName },
+}
+
+// statusNames are the net/http constants of the success statuses.
+var statusNames = map[int]string{
+	http.StatusOK:                   "http.StatusOK",
+	http.StatusCreated:              "http.StatusCreated",
+	http.StatusAccepted:             "http.StatusAccepted",
+	http.StatusNonAuthoritativeInfo: "http.StatusNonAuthoritativeInfo",
+	http.StatusNoContent:            "http.StatusNoContent",
+	http.StatusResetContent:         "http.StatusResetContent",
+	http.StatusPartialContent:       "http.StatusPartialContent",
+}
+
+// goStatus writes an HTTP status as a Go expression, by name when net/http
+// has one.
+func goStatus(status int) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return strconv.Itoa(status)
+}
+
+// durationUnits are the units goDuration writes durations in, largest first.
+var durationUnits = []struct {
+	unit time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+}
+
+// goDuration writes a duration of the model, like "1m30s", as a Go
+// expression: 90 * time.Second.
+func goDuration(s string) (string, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+	for _, unit := range durationUnits {
+		if d%unit.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.unit, unit.name), nil
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d), nil
+}
+
+// LoadTemplates parses the built-in template set. Every *.tmpl file of dir,
+// when given, is parsed on top of it, so a {{define}} there replaces the
+// template of the same name ("handler", "serveHTTP", "errors", ...).
+func LoadTemplates(dir string) (*template.Template, error) {
+	tmpl, err := template.New("apigen").Funcs(templateFuncs).ParseFS(defaultTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return tmpl, nil
+	}
+	return tmpl.ParseGlob(filepath.Join(dir, "*.tmpl"))
+}
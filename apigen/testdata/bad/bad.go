@@ -0,0 +1,195 @@
+package bad
+
+import (
+	"context"
+	"time"
+)
+
+type Api struct{}
+
+type Params struct {
+	Name  string     `apivalidator:"required,mni=3"`
+	Age   int        `apivalidator:"min=x"`
+	Score complex128 `apivalidator:"required"`
+}
+
+// apigen:api {"url": "/x", "methd": "POST"}
+func (srv *Api) X(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/y",}
+func (srv *Api) Y(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/z/{id}/x{y}"}
+func (srv *Api) Z(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/x", "method": ["GET", "FETCH"]}
+func (srv *Api) W(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/x"}
+func (srv *Api) V(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/u", "roles": ["admin", ""]}
+func (srv *Api) U(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/t", "auth_schemes": ["bearer", "digest"]}
+func (srv *Api) T(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/s", "timeout": "2"}
+func (srv *Api) S(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/r", "rate_limit": "10 per second"}
+func (srv *Api) R(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/q", "max_in_flight": -1}
+func (srv *Api) Q(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:service {"prefix": "svc/", "url": "/x"}
+type Service struct{}
+
+// apigen:api {"url": "/p", "status": 302}
+func (srv *Api) P(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/o", "body": "xml"}
+func (srv *Api) O(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+type Upload struct {
+	File []byte `apivalidator:"min=1,maxsize=1k"`
+	Name string `apivalidator:"mimetype=text/plain"`
+}
+
+// apigen:api {"url": "/n"}
+func (srv *Api) N(ctx context.Context, in Upload) (*Upload, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/m", "stream": "sse"}
+func (srv *Api) M(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/l", "stream": "ndjson", "timeout": "1s"}
+func (srv *Api) L(ctx context.Context, in Params) (<-chan Params, error) {
+	return nil, nil
+}
+
+// apigen:api {"url": "/k", "stream": "csv"}
+func (srv *Api) K(ctx context.Context, in Params) (chan<- Params, error) {
+	return nil, nil
+}
+
+// apigen:api {"url": "/j", "stream": "csv"}
+func (srv *Api) J(ctx context.Context, in Params) (chan Params, error) {
+	return nil, nil
+}
+
+// apigen:api {"url": "/i"}
+func (srv *Api) I(in Params, ctx context.Context) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/h"}
+func (srv *Api) H(ctx context.Context, in Params, more Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/g"}
+func (srv *Api) G(ctx context.Context, id int) (*Params, error) {
+	return nil, nil
+}
+
+// apigen:api {"url": "/f"}
+func (srv *Api) F(ctx context.Context) *Params {
+	return nil
+}
+
+// apigen:api {"url": "/e/{id}"}
+func (srv *Api) E(ctx context.Context) error {
+	return nil
+}
+
+type Generic[T any] struct{}
+
+// apigen:api {"url": "/d"}
+func (srv *Generic[T]) D(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/c"}
+func C(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/c"}
+func B(ctx context.Context, in Params) (*Params, error) {
+	return &in, nil
+}
+
+func CHandler() {}
+
+type Scalars struct {
+	On    bool          `apivalidator:"min=1"`
+	Small uint8         `apivalidator:"max=300"`
+	Ratio float32       `apivalidator:"min=x"`
+	Wait  time.Duration `apivalidator:"max=2"`
+	At    time.Time     `apivalidator:"default=now"`
+	Name  string        `apivalidator:"layout=DateOnly"`
+}
+
+// apigen:api {"url": "/a"}
+func (srv *Api) A(ctx context.Context, in Scalars) (*Scalars, error) {
+	return &in, nil
+}
+
+type ItemKey struct {
+	ID   int
+	Slug string
+}
+
+// apigen:api {"url": "/item/{id}", "method": "GET"}
+func (srv *Api) GetItem(ctx context.Context, in ItemKey) (*ItemKey, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/item/{slug}", "method": "DELETE"}
+func (srv *Api) DropItem(ctx context.Context, in ItemKey) (*ItemKey, error) {
+	return &in, nil
+}
+
+type Up struct {
+	Avatar []byte
+}
+
+// apigen:api {"url": "/up", "method": "POST", "body": "json"}
+func (srv *Api) SetUp(ctx context.Context, in Up) error {
+	return nil
+}
+
+// apigen:api {"url": "/twice"}
+// apigen:api {"url": "/twice", "method": "POST"}
+func (srv *Api) Twice(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package imports
+
+import (
+	"context"
+
+	"github.com/TerionGVS5/hw5_codegen/apigen/testdata/imports/json"
+)
+
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
+
+type Api struct{}
+
+// apigen:api {"url": "/in", "body": "json"}
+func (srv *Api) In(ctx context.Context, in json.In) (*json.In, error) {
+	return &in, nil
+}
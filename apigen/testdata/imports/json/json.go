@@ -0,0 +1,12 @@
+// Package json has the name of a package the generated code imports.
+package json
+
+import "time"
+
+// Wait is a duration declared outside of the generated package.
+type Wait time.Duration
+
+type In struct {
+	Name string
+	Wait Wait `apivalidator:"min=1s"`
+}
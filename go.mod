@@ -0,0 +1,3 @@
+module github.com/TerionGVS5/hw5_codegen
+
+go 1.22